@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=clusterdnsrecords,scope=Cluster,shortName=clusterrecords;clusterrecord;cdns
+// +kubebuilder:printcolumn:name="Active",type=boolean,JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Record",type=string,JSONPath=`.status.record`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Last Update",type=date,JSONPath=`.status.lastUpdateTime`,priority=1
+// +kubebuilder:printcolumn:name="Display Name",type=string,JSONPath=`.status.displayName`,priority=1
+
+// ClusterDNSRecord is the Schema for the cluster scoped clusterdnsrecords API
+type ClusterDNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSRecordSpec   `json:"spec,omitempty"`
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+// DNSRecord returns the record as a DNSRecord sharing its metadata, spec and status,
+// so that both kinds go through the same validation and reconciliation logic
+func (in *ClusterDNSRecord) DNSRecord() *DNSRecord {
+	return &DNSRecord{TypeMeta: in.TypeMeta, ObjectMeta: in.ObjectMeta, Spec: in.Spec, Status: in.Status}
+}
+
+// SetDNSRecord updates the record's metadata, spec and status from the DNSRecord
+func (in *ClusterDNSRecord) SetDNSRecord(r *DNSRecord) {
+	in.ObjectMeta = r.ObjectMeta
+	in.Spec = r.Spec
+	in.Status = r.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterDNSRecordList contains a list of ClusterDNSRecord
+type ClusterDNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterDNSRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterDNSRecord{}, &ClusterDNSRecordList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var clusterdnsrecordlog = logf.Log.WithName("clusterdnsrecord-resource")
+
+// SetupWebhookWithManager sets up the ClusterDNSRecord webhooks, the validation looks up the conflicting
+// records by name and requires the DNSRecord webhooks to be set up too
+func (in *ClusterDNSRecord) SetupWebhookWithManager(mgr ctrl.Manager, opts ...WebhookOption) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ClusterDNSRecord{}, recordNameKey, recordName); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(in).
+		WithDefaulter(newRecordDefaulter(mgr.GetClient(), opts...)).
+		WithValidator(&recordValidator{c: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dns-linka-cloud-v1alpha1-clusterdnsrecord,mutating=true,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=clusterdnsrecords,verbs=create;update,versions=v1alpha1,name=mclusterdnsrecord.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ClusterDNSRecord{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (in *ClusterDNSRecord) Default() {
+	r := in.DNSRecord()
+	r.Default()
+	in.SetDNSRecord(r)
+}
+
+// +kubebuilder:webhook:path=/validate-dns-linka-cloud-v1alpha1-clusterdnsrecord,mutating=false,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=clusterdnsrecords,verbs=create;update,versions=v1alpha1,name=vclusterdnsrecord.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ClusterDNSRecord{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *ClusterDNSRecord) ValidateCreate() error {
+	clusterdnsrecordlog.Info("validate create", "name", in.Name)
+	return in.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *ClusterDNSRecord) ValidateUpdate(old runtime.Object) error {
+	clusterdnsrecordlog.Info("validate update", "name", in.Name)
+	o, ok := old.(*ClusterDNSRecord)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ClusterDNSRecord but got a %T", old))
+	}
+	if !in.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return in.invalid(append(in.validateSpec(), in.DNSRecord().validateImmutable(o.DNSRecord())...))
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *ClusterDNSRecord) ValidateDelete() error {
+	return nil
+}
+
+func (in *ClusterDNSRecord) validate() error {
+	return in.invalid(in.validateSpec())
+}
+
+func (in *ClusterDNSRecord) validateSpec() field.ErrorList {
+	errs := in.Spec.validate()
+	// secrets are namespaced, a cluster record cannot reference them
+	if in.Spec.TLSA != nil && in.Spec.TLSA.SecretName != "" {
+		errs = append(errs, field.Forbidden(field.NewPath("spec").Child("tlsa").Child("secretName"), "secret references are not supported by cluster records"))
+	}
+	if in.Spec.DKIM != nil && in.Spec.DKIM.SecretName != "" {
+		errs = append(errs, field.Forbidden(field.NewPath("spec").Child("dkim").Child("secretName"), "secret references are not supported by cluster records"))
+	}
+	return errs
+}
+
+func (in *ClusterDNSRecord) invalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "ClusterDNSRecord"}, in.Name, errs)
+}
@@ -0,0 +1,132 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DNSDomainPolicySpec defines the desired state of DNSDomainPolicy
+type DNSDomainPolicySpec struct {
+	// Domains are the domains restricted by the policy, including their subdomains, e.g. example.org.
+	Domains []string `json:"domains"`
+	// Namespaces are the namespaces allowed to define records in the domains
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces allowed to define records in the domains
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Types are the allowed record types, e.g. A, CNAME, all types are allowed when empty.
+	// TXT allows the SPF, DMARC and DKIM records
+	// +optional
+	Types []string `json:"types,omitempty"`
+}
+
+// DNSDomainPolicyStatus defines the observed state of DNSDomainPolicy
+type DNSDomainPolicyStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=dnsdomainpolicies,scope=Cluster,shortName=domainpolicies;domainpolicy
+// +kubebuilder:printcolumn:name="Domains",type=string,JSONPath=`.spec.domains`
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`
+// +kubebuilder:printcolumn:name="Types",type=string,JSONPath=`.spec.types`
+
+// DNSDomainPolicy is the Schema for the dnsdomainpolicies API
+type DNSDomainPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSDomainPolicySpec   `json:"spec,omitempty"`
+	Status DNSDomainPolicyStatus `json:"status,omitempty"`
+}
+
+// Matches returns true if the name belongs to one of the policy's domains
+func (in *DNSDomainPolicy) Matches(name string) bool {
+	name = dns.Fqdn(strings.ToLower(name))
+	for _, v := range in.Spec.Domains {
+		if dns.IsSubDomain(dns.Fqdn(strings.ToLower(v)), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if the namespace is allowed to define records of the given type
+func (in *DNSDomainPolicy) Allows(ns *corev1.Namespace, typ string) bool {
+	if len(in.Spec.Types) != 0 && !stringIn(typ, in.Spec.Types) {
+		// the mail policy records are TXT records
+		if typ != "SPF" && typ != "DMARC" && typ != "DKIM" || !stringIn("TXT", in.Spec.Types) {
+			return false
+		}
+	}
+	if stringIn(ns.Name, in.Spec.Namespaces) {
+		return true
+	}
+	if in.Spec.NamespaceSelector == nil {
+		return false
+	}
+	s, err := metav1.LabelSelectorAsSelector(in.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(ns.Labels))
+}
+
+// CheckDomainPolicies returns an error if the policies matching the record's name do not allow the namespace
+// to define it, the records matching no policy are allowed
+func (in *DNSRecord) CheckDomainPolicies(policies []DNSDomainPolicy, ns *corev1.Namespace) error {
+	typ, name := in.Spec.typeAndName()
+	if name == "" {
+		return nil
+	}
+	var matched []string
+	for i := range policies {
+		p := &policies[i]
+		if !p.Matches(name) {
+			continue
+		}
+		if p.Allows(ns, typ) {
+			return nil
+		}
+		matched = append(matched, p.Name)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	return fmt.Errorf("namespace %s is not allowed to define %s records for %s (DNSDomainPolicy: %s)", ns.Name, typ, name, strings.Join(matched, ", "))
+}
+
+// +kubebuilder:object:root=true
+
+// DNSDomainPolicyList contains a list of DNSDomainPolicy
+type DNSDomainPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSDomainPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSDomainPolicy{}, &DNSDomainPolicyList{})
+}
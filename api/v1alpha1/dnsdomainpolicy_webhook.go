@@ -0,0 +1,101 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var dnsdomainpolicylog = logf.Log.WithName("dnsdomainpolicy-resource")
+
+func (in *DNSDomainPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(in).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dns-linka-cloud-v1alpha1-dnsdomainpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=dnsdomainpolicies,verbs=create;update,versions=v1alpha1,name=mdnsdomainpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &DNSDomainPolicy{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (in *DNSDomainPolicy) Default() {
+	for i := range in.Spec.Domains {
+		in.Spec.Domains[i] = fqdn(in.Spec.Domains[i])
+	}
+	for i := range in.Spec.Types {
+		in.Spec.Types[i] = strings.ToUpper(in.Spec.Types[i])
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-dns-linka-cloud-v1alpha1-dnsdomainpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=dnsdomainpolicies,verbs=create;update,versions=v1alpha1,name=vdnsdomainpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &DNSDomainPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSDomainPolicy) ValidateCreate() error {
+	dnsdomainpolicylog.Info("validate create", "name", in.Name)
+	return in.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSDomainPolicy) ValidateUpdate(old runtime.Object) error {
+	dnsdomainpolicylog.Info("validate update", "name", in.Name)
+	return in.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSDomainPolicy) ValidateDelete() error {
+	return nil
+}
+
+func (in *DNSDomainPolicy) validate() error {
+	var errs field.ErrorList
+	if len(in.Spec.Domains) == 0 {
+		errs = append(errs, field.Required(field.NewPath("spec").Child("domains"), "at least one domain is required"))
+	}
+	for i, v := range in.Spec.Domains {
+		if _, ok := dns.IsDomainName(v); !ok || v == "." {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("domains").Index(i), v, "domain must be a valid dns name"))
+		}
+	}
+	for i, v := range in.Spec.Types {
+		if _, ok := dns.StringToType[v]; !ok && !stringIn(v, []string{"SPF", "DMARC", "DKIM"}) {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("types").Index(i), v, "unknown record type"))
+		}
+	}
+	if in.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(in.Spec.NamespaceSelector); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("namespaceSelector"), in.Spec.NamespaceSelector, err.Error()))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "DNSDomainPolicy"}, in.Name, errs)
+}
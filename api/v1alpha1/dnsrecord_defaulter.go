@@ -0,0 +1,201 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DefaultOriginAnnotation is the Namespace annotation setting the origin in which
+// the relative names of the namespace's records are expanded, e.g. example.org
+const DefaultOriginAnnotation = "dns.linka.cloud/default-origin"
+
+// WebhookOption configures the records webhooks
+// +kubebuilder:object:generate=false
+type WebhookOption func(d *recordDefaulter)
+
+// WithDefaultTTL sets the TTL of the records not setting one when neither their namespace nor their zone define one
+func WithDefaultTTL(ttl uint32) WebhookOption {
+	return func(d *recordDefaulter) {
+		d.ttl = ttl
+	}
+}
+
+// WithDefaultOrigin sets the origin in which the relative names are expanded
+// when the record's namespace does not define one
+func WithDefaultOrigin(origin string) WebhookOption {
+	return func(d *recordDefaulter) {
+		d.origin = origin
+	}
+}
+
+// recordDefaulter expands the records relative names in their default origin,
+// resolves their TTL, then defaults them
+type recordDefaulter struct {
+	c      client.Reader
+	origin string
+	ttl    uint32
+}
+
+var _ webhook.CustomDefaulter = &recordDefaulter{}
+
+func newRecordDefaulter(c client.Reader, opts ...WebhookOption) *recordDefaulter {
+	d := &recordDefaulter{c: c, ttl: DefaultTTL}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+func (d *recordDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *DNSRecord:
+		origin, err := d.originFor(ctx, o.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := o.Spec.expand(origin); err != nil {
+			return apierrors.NewBadRequest(err.Error())
+		}
+		if err := o.ResolveTTL(ctx, d.c, d.ttl); err != nil {
+			return err
+		}
+		o.Default()
+		return nil
+	case *ClusterDNSRecord:
+		if err := o.Spec.expand(d.origin); err != nil {
+			return apierrors.NewBadRequest(err.Error())
+		}
+		r := o.DNSRecord()
+		if err := r.ResolveTTL(ctx, d.c, d.ttl); err != nil {
+			return err
+		}
+		o.SetDNSRecord(r)
+		o.Default()
+		return nil
+	}
+	return apierrors.NewBadRequest(fmt.Sprintf("expected a DNSRecord or a ClusterDNSRecord but got a %T", obj))
+}
+
+// originFor returns the namespace's default origin, falling back to the controller one
+func (d *recordDefaulter) originFor(ctx context.Context, namespace string) (string, error) {
+	var ns corev1.Namespace
+	if err := d.c.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return d.origin, nil
+		}
+		return "", err
+	}
+	if o := ns.Annotations[DefaultOriginAnnotation]; o != "" {
+		return o, nil
+	}
+	return d.origin, nil
+}
+
+// expand expands the relative names and targets in the origin, as in a zone file
+func (r *DNSRecordSpec) expand(origin string) error {
+	if origin == "" {
+		return nil
+	}
+	origin = dns.Fqdn(origin)
+	if _, ok := dns.IsDomainName(origin); !ok {
+		return fmt.Errorf("invalid default origin: %s", origin)
+	}
+	if r.Raw != "" {
+		zp := dns.NewZoneParser(strings.NewReader(r.Raw), origin, "")
+		rr, _ := zp.Next()
+		if err := zp.Err(); err != nil {
+			return fmt.Errorf("invalid raw record: %w", err)
+		}
+		if rr != nil {
+			r.Raw = rr.String()
+		}
+		return nil
+	}
+	for _, v := range r.names() {
+		*v = absolute(*v, origin)
+	}
+	return nil
+}
+
+// names returns the record's owner name and the domain names in its data
+func (r *DNSRecordSpec) names() []*string {
+	switch {
+	case r.A != nil:
+		return []*string{&r.A.Name}
+	case r.AAAA != nil:
+		return []*string{&r.AAAA.Name}
+	case r.CNAME != nil:
+		return []*string{&r.CNAME.Name, &r.CNAME.Target}
+	case r.TXT != nil:
+		return []*string{&r.TXT.Name}
+	case r.SRV != nil:
+		return []*string{&r.SRV.Name, &r.SRV.Target}
+	case r.MX != nil:
+		return []*string{&r.MX.Name, &r.MX.Target}
+	case r.CAA != nil:
+		return []*string{&r.CAA.Name}
+	case r.NS != nil:
+		names := []*string{&r.NS.Name}
+		for i := range r.NS.Targets {
+			names = append(names, &r.NS.Targets[i])
+		}
+		return names
+	case r.PTR != nil:
+		// the ip addresses are converted to their reverse name by Default
+		if net.ParseIP(r.PTR.Name) != nil {
+			return []*string{&r.PTR.Target}
+		}
+		return []*string{&r.PTR.Name, &r.PTR.Target}
+	case r.SVCB != nil:
+		return []*string{&r.SVCB.Name, &r.SVCB.Target}
+	case r.HTTPS != nil:
+		return []*string{&r.HTTPS.Name, &r.HTTPS.Target}
+	case r.TLSA != nil:
+		return []*string{&r.TLSA.Name}
+	case r.SPF != nil:
+		return []*string{&r.SPF.Name}
+	case r.DMARC != nil:
+		return []*string{&r.DMARC.Name}
+	case r.DKIM != nil:
+		return []*string{&r.DKIM.Name}
+	}
+	return nil
+}
+
+// absolute returns the name expanded in the origin as in a zone file: @ is the origin itself
+// and the names not ending with a dot are relative to the origin
+func absolute(name, origin string) string {
+	switch {
+	case name == "", name == ".", strings.HasSuffix(name, "."):
+		return name
+	case name == "@":
+		return origin
+	}
+	return name + "." + origin
+}
@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAbsolute(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "origin", in: "@", want: "example.org."},
+		{name: "relative", in: "www", want: "www.example.org."},
+		{name: "dotted", in: "www.dev", want: "www.dev.example.org."},
+		{name: "already under the origin", in: "www.example.org", want: "www.example.org.example.org."},
+		{name: "foreign domain", in: "www.example.com", want: "www.example.com.example.org."},
+		{name: "absolute", in: "www.example.com.", want: "www.example.com."},
+		{name: "root", in: ".", want: "."},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, absolute(tt.in, "example.org."))
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin string
+		spec   DNSRecordSpec
+		want   DNSRecordSpec
+		err    string
+	}{
+		{
+			name: "no origin",
+			spec: DNSRecordSpec{CNAME: &CNAMERecord{Name: "www", Target: "@"}},
+			want: DNSRecordSpec{CNAME: &CNAMERecord{Name: "www", Target: "@"}},
+		},
+		{
+			name:   "name and target",
+			origin: "example.org",
+			spec:   DNSRecordSpec{CNAME: &CNAMERecord{Name: "www", Target: "@"}},
+			want:   DNSRecordSpec{CNAME: &CNAMERecord{Name: "www.example.org.", Target: "example.org."}},
+		},
+		{
+			name:   "dotted and absolute names",
+			origin: "example.org.",
+			spec:   DNSRecordSpec{MX: &MXRecord{Name: "mail.dev", Target: "mx.example.com."}},
+			want:   DNSRecordSpec{MX: &MXRecord{Name: "mail.dev.example.org.", Target: "mx.example.com."}},
+		},
+		{
+			name:   "name already under the origin",
+			origin: "example.org",
+			spec:   DNSRecordSpec{A: &ARecord{Name: "www.example.org", Targets: []string{"10.0.0.1"}}},
+			want:   DNSRecordSpec{A: &ARecord{Name: "www.example.org.example.org.", Targets: []string{"10.0.0.1"}}},
+		},
+		{
+			name:   "foreign domain",
+			origin: "example.org",
+			spec:   DNSRecordSpec{A: &ARecord{Name: "www.example.com", Targets: []string{"10.0.0.1"}}},
+			want:   DNSRecordSpec{A: &ARecord{Name: "www.example.com.example.org.", Targets: []string{"10.0.0.1"}}},
+		},
+		{
+			name:   "name servers",
+			origin: "example.org",
+			spec:   DNSRecordSpec{NS: &NSRecord{Name: "dev", Targets: []string{"ns1", "ns2.example.com."}}},
+			want:   DNSRecordSpec{NS: &NSRecord{Name: "dev.example.org.", Targets: []string{"ns1.example.org.", "ns2.example.com."}}},
+		},
+		{
+			name:   "ptr with an ip address",
+			origin: "example.org",
+			spec:   DNSRecordSpec{PTR: &PTRRecord{Name: "192.0.2.10", Target: "www"}},
+			want:   DNSRecordSpec{PTR: &PTRRecord{Name: "192.0.2.10", Target: "www.example.org."}},
+		},
+		{
+			name:   "raw",
+			origin: "example.org",
+			spec:   DNSRecordSpec{Raw: "www 60 IN CNAME @"},
+			want:   DNSRecordSpec{Raw: "www.example.org.\t60\tIN\tCNAME\texample.org."},
+		},
+		{
+			name:   "invalid raw",
+			origin: "example.org",
+			spec:   DNSRecordSpec{Raw: "www 60 IN CNAME"},
+			err:    "invalid raw record",
+		},
+		{
+			name:   "invalid origin",
+			origin: "example..org",
+			spec:   DNSRecordSpec{CNAME: &CNAMERecord{Name: "www", Target: "@"}},
+			err:    "invalid default origin: example..org.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.expand(tt.origin)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tt.spec)
+		})
+	}
+}
+
+func TestOriginFor(t *testing.T) {
+	namespace := func(name, origin string) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if origin != "" {
+			ns.Annotations = map[string]string{DefaultOriginAnnotation: origin}
+		}
+		return ns
+	}
+	c := newClient(t, namespace("annotated", "apps.example.org"), namespace("default", ""))
+	tests := []struct {
+		name      string
+		namespace string
+		origin    string
+		want      string
+	}{
+		{name: "namespace origin", namespace: "annotated", origin: "example.org", want: "apps.example.org"},
+		{name: "controller origin", namespace: "default", origin: "example.org", want: "example.org"},
+		{name: "no origin", namespace: "default"},
+		{name: "missing namespace", namespace: "missing", origin: "example.org", want: "example.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRecordDefaulter(c, WithDefaultOrigin(tt.origin)).originFor(context.Background(), tt.namespace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
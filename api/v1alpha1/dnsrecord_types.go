@@ -17,17 +17,34 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"net"
+
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// DNSRecordSpec defines the desired state of DNSRecord
+// DNSRecordSpec defines the desired state of DNSRecord.
+// When a default origin is set, by the namespace's dns.linka.cloud/default-origin annotation or by the operator,
+// every name and target not ending with a dot is relative to it as in a zone file, "@" being the origin itself:
+// with the example.org. origin, www.example.com is expanded to www.example.com.example.org.
+// Without default origin, the names are made absolute.
 type DNSRecordSpec struct {
 	Active *bool        `json:"active,omitempty"`
 	A      *ARecord     `json:"a,omitempty"`
+	AAAA   *AAAARecord  `json:"aaaa,omitempty"`
 	CNAME  *CNAMERecord `json:"cname,omitempty"`
 	TXT    *TXTRecord   `json:"txt,omitempty"`
 	SRV    *SRVRecord   `json:"srv,omitempty"`
 	MX     *MXRecord    `json:"mx,omitempty"`
+	CAA    *CAARecord   `json:"caa,omitempty"`
+	NS     *NSRecord    `json:"ns,omitempty"`
+	PTR    *PTRRecord   `json:"ptr,omitempty"`
+	SVCB   *SVCBRecord  `json:"svcb,omitempty"`
+	HTTPS  *SVCBRecord  `json:"https,omitempty"`
+	TLSA   *TLSARecord  `json:"tlsa,omitempty"`
+	SPF    *SPFRecord   `json:"spf,omitempty"`
+	DMARC  *DMARCRecord `json:"dmarc,omitempty"`
+	DKIM   *DKIMRecord  `json:"dkim,omitempty"`
 	// Raw is an RFC 1035 style record string that github.com/miekg/dns will try to parse
 	// +optional
 	Raw string `json:"raw,omitempty"`
@@ -38,15 +55,78 @@ type DNSRecordStatus struct {
 	Record   string `json:"record,omitempty"`
 	Active   *bool  `json:"active,omitempty"`
 	Provider string `json:"provider,omitempty"`
-	ID       string `json:"id,omitempty"`
+	// Deprecated: ID is only read to migrate records created before IDs was introduced
+	ID string `json:"id,omitempty"`
+	// IDs are the provider's records ids, one per record value
+	IDs []string `json:"ids,omitempty"`
+	// DisplayName is the record's name Unicode form, only set for internationalized names
+	DisplayName string `json:"displayName,omitempty"`
+	// Zone is the DNSZone origin the record belongs to, empty when no DNSZone is declared
+	Zone string `json:"zone,omitempty"`
+	// SecretData is the record data computed from the Secret referenced by a TLSA or DKIM record:
+	// the TLSA certificate association data or the DKIM public key
+	SecretData string `json:"secretData,omitempty"`
+	// ObservedGeneration is the record's generation last synced with the provider
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastUpdateTime is the last time the record's synced state changed after a successful provider sync:
+	// its provider records, zone, conditions or observed generation. It is not updated by the syncs changing nothing
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ConditionReady reports whether the record is synced and served, its reason is the one of the first failing condition
+	ConditionReady = "Ready"
+	// ConditionZone reports whether the record belongs to a declared DNSZone
+	ConditionZone = "Zone"
+	// ConditionProviderSynced reports whether the provider's records match the record
+	ConditionProviderSynced = "ProviderSynced"
+	// ConditionPropagated reports whether the DNS verification server answers with the record's desired state
+	ConditionPropagated = "Propagated"
+	// ConditionConflict reports whether the record conflicts with records it does not own
+	ConditionConflict = "Conflict"
+	// ConditionForbidden reports whether the record's namespace is not allowed to define it by a DNSDomainPolicy
+	ConditionForbidden = "Forbidden"
+
+	ReasonReady         = "Ready"
+	ReasonReconciling   = "Reconciling"
+	ReasonInvalidRecord = "InvalidRecord"
+	ReasonZoneFound     = "ZoneFound"
+	ReasonZoneNotFound  = "ZoneNotFound"
+	ReasonSynced        = "Synced"
+	ReasonSyncFailed    = "SyncFailed"
+	ReasonPropagated    = "Propagated"
+	ReasonPropagating   = "Propagating"
+	ReasonNoConflict    = "NoConflict"
+	ReasonRecordExists  = "RecordExists"
+	ReasonDomainPolicy  = "DomainPolicy"
+)
+
+// SetCondition sets the record's condition for its current generation
+func (in *DNSRecord) SetCondition(typ string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:               typ,
+		Status:             status,
+		ObservedGeneration: in.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:storageversion
 // +kubebuilder:resource:scope=Namespaced
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=dnsrecords,shortName=records;record;dns
 // +kubebuilder:printcolumn:name="Active",type=boolean,JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Record",type=string,JSONPath=`.status.record`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Last Update",type=date,JSONPath=`.status.lastUpdateTime`,priority=1
+// +kubebuilder:printcolumn:name="Display Name",type=string,JSONPath=`.status.displayName`,priority=1
 
 // DNSRecord is the Schema for the dnsrecords API
 type DNSRecord struct {
@@ -72,8 +152,63 @@ type ARecord struct {
 	Class uint16 `json:"class,omitempty"`
 	// +optional
 	Ttl uint32 `json:"ttl"`
+	// Target is merged into Targets by the mutating webhook, it is kept for compatibility
 	// TODO(adphi): support service, e.g. default/kubernetes
+	// +optional
+	Target string `json:"target,omitempty"`
+	// Targets are the record's addresses, forming a single RRset
+	// +optional
+	Targets []string `json:"targets,omitempty"`
+}
+
+type AAAARecord struct {
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// Target is merged into Targets by the mutating webhook, it is kept for compatibility
+	// +optional
 	Target string `json:"target,omitempty"`
+	// Targets are the record's addresses, forming a single RRset
+	// +optional
+	Targets []string `json:"targets,omitempty"`
+}
+
+// AllTargets returns the Targets, including the legacy Target if set
+func (r *ARecord) AllTargets() []string {
+	return mergeTargets(r.Target, r.Targets)
+}
+
+// AllTargets returns the Targets, including the legacy Target if set
+func (r *AAAARecord) AllTargets() []string {
+	return mergeTargets(r.Target, r.Targets)
+}
+
+// ipv6Only returns true if the record only has IPv6 targets
+func (r *ARecord) ipv6Only() bool {
+	if r == nil {
+		return false
+	}
+	targets := r.AllTargets()
+	for _, v := range targets {
+		if ip := net.ParseIP(v); ip == nil || ip.To4() != nil {
+			return false
+		}
+	}
+	return len(targets) != 0
+}
+
+func mergeTargets(target string, targets []string) []string {
+	if target == "" {
+		return targets
+	}
+	for _, v := range targets {
+		if v == target {
+			return targets
+		}
+	}
+	return append([]string{target}, targets...)
 }
 
 type TXTRecord struct {
@@ -110,6 +245,169 @@ type MXRecord struct {
 	Target     string `json:"target,omitempty"`
 }
 
+type CAARecord struct {
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// Flag is either 0 or 128 (issuer critical)
+	// +optional
+	Flag uint8 `json:"flag,omitempty"`
+	// Tag is one of issue, issuewild or iodef
+	// +kubebuilder:validation:Enum=issue;issuewild;iodef
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+type NSRecord struct {
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl     uint32   `json:"ttl"`
+	Targets []string `json:"targets"`
+}
+
+type PTRRecord struct {
+	// Name is the reverse name, e.g. 10.2.0.192.in-addr.arpa., an ip address is converted to its reverse name
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl    uint32 `json:"ttl"`
+	Target string `json:"target"`
+}
+
+// SVCBRecord is used by both SVCB and HTTPS records
+type SVCBRecord struct {
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// Priority 0 is the alias mode, no parameters are allowed then
+	// +optional
+	Priority uint16 `json:"priority"`
+	// Target is the alternative endpoint name, "." means the record's name
+	Target string `json:"target"`
+	// Alpn are the supported protocols, e.g. h2, h3
+	// +optional
+	Alpn []string `json:"alpn,omitempty"`
+	// NoDefaultAlpn disables the protocol's default alpn (http/1.1 for HTTPS records)
+	// +optional
+	NoDefaultAlpn bool `json:"noDefaultAlpn,omitempty"`
+	// +optional
+	Port uint16 `json:"port,omitempty"`
+	// +optional
+	IPv4Hint []string `json:"ipv4hint,omitempty"`
+	// +optional
+	IPv6Hint []string `json:"ipv6hint,omitempty"`
+	// ECH is the base64 encoded ECHConfigList
+	// +optional
+	ECH string `json:"ech,omitempty"`
+}
+
+type TLSARecord struct {
+	// Name is the service's name, e.g. _25._tcp.mail.example.org.
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// Usage is one of 0 (PKIX-TA), 1 (PKIX-EE), 2 (DANE-TA) or 3 (DANE-EE)
+	// +kubebuilder:validation:Maximum=3
+	Usage uint8 `json:"usage"`
+	// Selector is either 0 (full certificate) or 1 (SubjectPublicKeyInfo)
+	// +kubebuilder:validation:Maximum=1
+	Selector uint8 `json:"selector"`
+	// MatchingType is one of 0 (exact match), 1 (SHA-256) or 2 (SHA-512)
+	// +kubebuilder:validation:Maximum=2
+	MatchingType uint8 `json:"matchingType"`
+	// Certificate is the hex encoded certificate association data, it is ignored when SecretName is set,
+	// the data computed by the controller being stored in the status
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+	// SecretName is the name of a kubernetes.io/tls Secret in the record's namespace.
+	// The end entity usages (1 and 3) use the first certificate of tls.crt,
+	// the trust anchor usages (0 and 2) use ca.crt, or the last certificate of tls.crt if missing
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// SPFRecord is rendered as a "v=spf1" TXT record
+type SPFRecord struct {
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// Mechanisms are the policy's mechanisms and modifiers, e.g. mx, include:_spf.google.com, ip4:192.0.2.0/24, -all
+	Mechanisms []string `json:"mechanisms"`
+}
+
+// DMARCRecord is rendered as a "v=DMARC1" TXT record
+type DMARCRecord struct {
+	// Name is the policy's domain, the _dmarc label is added if missing
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// +kubebuilder:validation:Enum=none;quarantine;reject
+	Policy string `json:"policy"`
+	// +kubebuilder:validation:Enum=none;quarantine;reject
+	// +optional
+	SubdomainPolicy string `json:"subdomainPolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *uint8 `json:"percent,omitempty"`
+	// ReportAggregate are the aggregate reports uris, e.g. mailto:dmarc@example.org
+	// +optional
+	ReportAggregate []string `json:"rua,omitempty"`
+	// ReportFailure are the failure reports uris
+	// +optional
+	ReportFailure []string `json:"ruf,omitempty"`
+	// AlignmentDKIM is either r (relaxed) or s (strict)
+	// +kubebuilder:validation:Enum=r;s
+	// +optional
+	AlignmentDKIM string `json:"adkim,omitempty"`
+	// AlignmentSPF is either r (relaxed) or s (strict)
+	// +kubebuilder:validation:Enum=r;s
+	// +optional
+	AlignmentSPF string `json:"aspf,omitempty"`
+	// FailureOptions are the colon separated failure reporting options, e.g. 1:d
+	// +optional
+	FailureOptions string `json:"fo,omitempty"`
+}
+
+// DKIMRecord is rendered as a "v=DKIM1" TXT record
+type DKIMRecord struct {
+	// Name is the selector's name, e.g. mail._domainkey.example.org.
+	Name string `json:"name"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	Ttl uint32 `json:"ttl"`
+	// +kubebuilder:validation:Enum=rsa;ed25519
+	// +optional
+	KeyType string `json:"keyType,omitempty"`
+	// PublicKey is the base64 encoded public key, it is ignored when SecretName is set,
+	// the key computed by the controller being stored in the status
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+	// SecretName is the name of a Secret in the record's namespace holding the PEM encoded private or public key
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// SecretKey is the key's name in the Secret, defaults to dkim.key
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+	// Testing marks the domain as testing DKIM (t=y)
+	// +optional
+	Testing bool `json:"testing,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // DNSRecordList contains a list of DNSRecord
@@ -0,0 +1,241 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// recordNameKey indexes the DNSRecords and ClusterDNSRecords by their lower cased fully qualified name
+const recordNameKey = ".spec.name"
+
+// recordValidator runs the records validation, then checks that they do not conflict
+// with the other records read from the manager's cache
+type recordValidator struct {
+	c client.Reader
+}
+
+var _ webhook.CustomValidator = &recordValidator{}
+
+func (v *recordValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *DNSRecord:
+		if err := o.ValidateCreate(); err != nil {
+			return err
+		}
+		errs := append(v.conflicts(ctx, recordKey(o), o), v.policies(ctx, o)...)
+		return o.invalid(append(errs, v.quota(ctx, o, nil)...))
+	case *ClusterDNSRecord:
+		if err := o.ValidateCreate(); err != nil {
+			return err
+		}
+		return o.invalid(v.conflicts(ctx, clusterRecordKey(o), o.DNSRecord()))
+	}
+	return apierrors.NewBadRequest(fmt.Sprintf("expected a DNSRecord or a ClusterDNSRecord but got a %T", obj))
+}
+
+func (v *recordValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	switch o := newObj.(type) {
+	case *DNSRecord:
+		// the finalizer removal must not be blocked
+		if !o.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		if err := o.ValidateUpdate(oldObj); err != nil {
+			return err
+		}
+		errs := append(v.conflicts(ctx, recordKey(o), o), v.policies(ctx, o)...)
+		return o.invalid(append(errs, v.quota(ctx, o, oldObj.(*DNSRecord))...))
+	case *ClusterDNSRecord:
+		if !o.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		if err := o.ValidateUpdate(oldObj); err != nil {
+			return err
+		}
+		return o.invalid(v.conflicts(ctx, clusterRecordKey(o), o.DNSRecord()))
+	}
+	return apierrors.NewBadRequest(fmt.Sprintf("expected a DNSRecord or a ClusterDNSRecord but got a %T", newObj))
+}
+
+func (v *recordValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+// conflicts returns the errors caused by the records sharing the record's name:
+// a CNAME record cannot coexist with other data nor be defined at a zone apex,
+// and the same record cannot be defined twice
+func (v *recordValidator) conflicts(ctx context.Context, key string, r *DNSRecord) (errs field.ErrorList) {
+	typ, name := r.Spec.typeAndName()
+	// invalid specs are reported by the spec validation
+	if typ == "" || name == "" {
+		return nil
+	}
+	name = normalizedName(name)
+	path := field.NewPath("spec").Child(strings.ToLower(typ)).Child("name")
+	if r.Spec.Raw != "" {
+		path = field.NewPath("spec").Child("raw")
+	}
+	if typ == "CNAME" {
+		zone, err := ZoneFor(ctx, v.c, name)
+		if err != nil {
+			return field.ErrorList{field.InternalError(path, err)}
+		}
+		if zone == name {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("a CNAME record cannot be defined at the %s zone apex", zone)))
+		}
+	}
+	others, err := v.records(ctx, name)
+	if err != nil {
+		return append(errs, field.InternalError(path, err))
+	}
+	keys := make([]string, 0, len(others))
+	for k := range others {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		o := others[k]
+		if k == key || !o.DeletionTimestamp.IsZero() {
+			continue
+		}
+		oTyp, oName := o.Spec.typeAndName()
+		if normalizedName(oName) != name {
+			continue
+		}
+		switch {
+		case typ == oTyp && sameData(r.Spec, o.Spec):
+			errs = append(errs, field.Invalid(path, name, fmt.Sprintf("the %s record is already defined by %s", typ, k)))
+		case typ == "CNAME" || oTyp == "CNAME":
+			errs = append(errs, field.Invalid(path, name, fmt.Sprintf("the %s record conflicts with the %s record defined by %s: a CNAME record cannot coexist with other data", typ, oTyp, k)))
+		}
+	}
+	return errs
+}
+
+// policies returns an error if the DNSDomainPolicies do not allow the record's namespace to define it
+func (v *recordValidator) policies(ctx context.Context, r *DNSRecord) field.ErrorList {
+	var policies DNSDomainPolicyList
+	if err := v.c.List(ctx, &policies); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), err)}
+	}
+	if len(policies.Items) == 0 {
+		return nil
+	}
+	var ns corev1.Namespace
+	if err := v.c.Get(ctx, client.ObjectKey{Name: r.Namespace}, &ns); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), err)}
+	}
+	if err := r.CheckDomainPolicies(policies.Items, &ns); err != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec"), err.Error())}
+	}
+	return nil
+}
+
+// quota returns the errors caused by the record exceeding its namespace's quota
+func (v *recordValidator) quota(ctx context.Context, r, old *DNSRecord) field.ErrorList {
+	var ns corev1.Namespace
+	if err := v.c.Get(ctx, client.ObjectKey{Name: r.Namespace}, &ns); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), err)}
+	}
+	q, err := QuotaFor(&ns)
+	if err != nil {
+		// a misconfigured namespace must not block the admission of all its records
+		dnsrecordlog.Error(err, "ignoring the invalid quota annotations", "namespace", ns.Name)
+	}
+	if q == nil {
+		return nil
+	}
+	var recs DNSRecordList
+	if err := v.c.List(ctx, &recs, client.InNamespace(r.Namespace)); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), err)}
+	}
+	var others []DNSRecord
+	for _, o := range recs.Items {
+		if o.Name != r.Name && o.DeletionTimestamp.IsZero() {
+			others = append(others, o)
+		}
+	}
+	return q.check(r, old, others)
+}
+
+// records returns the DNSRecords and ClusterDNSRecords named name, by kind and name
+func (v *recordValidator) records(ctx context.Context, name string) (map[string]*DNSRecord, error) {
+	var recs DNSRecordList
+	if err := v.c.List(ctx, &recs, client.MatchingFields{recordNameKey: name}); err != nil {
+		return nil, err
+	}
+	var crecs ClusterDNSRecordList
+	if err := v.c.List(ctx, &crecs, client.MatchingFields{recordNameKey: name}); err != nil {
+		return nil, err
+	}
+	out := make(map[string]*DNSRecord, len(recs.Items)+len(crecs.Items))
+	for i := range recs.Items {
+		out[recordKey(&recs.Items[i])] = &recs.Items[i]
+	}
+	for i := range crecs.Items {
+		out[clusterRecordKey(&crecs.Items[i])] = crecs.Items[i].DNSRecord()
+	}
+	return out, nil
+}
+
+// recordName returns the DNSRecord's or ClusterDNSRecord's normalized name for the recordNameKey index
+func recordName(o client.Object) []string {
+	var spec DNSRecordSpec
+	switch r := o.(type) {
+	case *DNSRecord:
+		spec = r.Spec
+	case *ClusterDNSRecord:
+		spec = r.Spec
+	default:
+		return nil
+	}
+	if _, name := spec.typeAndName(); name != "" {
+		return []string{normalizedName(name)}
+	}
+	return nil
+}
+
+func normalizedName(name string) string {
+	return dns.Fqdn(strings.ToLower(name))
+}
+
+func recordKey(r *DNSRecord) string {
+	return fmt.Sprintf("DNSRecord %s/%s", r.Namespace, r.Name)
+}
+
+func clusterRecordKey(r *ClusterDNSRecord) string {
+	return fmt.Sprintf("ClusterDNSRecord %s", r.Name)
+}
+
+// sameData returns true if both specs define the same record
+func sameData(a, b DNSRecordSpec) bool {
+	a.Active, b.Active = nil, nil
+	return reflect.DeepEqual(a, b)
+}
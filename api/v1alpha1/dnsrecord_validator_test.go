@@ -0,0 +1,130 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func newValidator(t *testing.T, objs ...client.Object) *recordValidator {
+	return &recordValidator{c: newClient(t, objs...)}
+}
+
+func TestValidateUpdateDeletedConflict(t *testing.T) {
+	spec := DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}}
+	a := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Finalizers: []string{"dns.linka.cloud/finalizer"}},
+		Spec:       spec,
+	}
+	b := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", Finalizers: []string{"dns.linka.cloud/finalizer"}},
+		Spec:       spec,
+	}
+	v := newValidator(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, a, b)
+	ctx := context.Background()
+
+	r := a.DeepCopy()
+	r.Finalizers = nil
+	require.Error(t, v.ValidateUpdate(ctx, a, r), "the records conflict")
+
+	// the finalizer removal of the deleted record is accepted
+	now := metav1.Now()
+	old := a.DeepCopy()
+	old.DeletionTimestamp = &now
+	r = old.DeepCopy()
+	r.Finalizers = nil
+	assert.NoError(t, v.ValidateUpdate(ctx, old, r))
+}
+
+func TestValidateUpdateDeletedForbidden(t *testing.T) {
+	r := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Finalizers: []string{"dns.linka.cloud/finalizer"}},
+		Spec:       DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}},
+	}
+	policy := &DNSDomainPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec:       DNSDomainPolicySpec{Domains: []string{"example.org"}, Namespaces: []string{"other"}},
+	}
+	v := newValidator(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, r, policy)
+	ctx := context.Background()
+
+	n := r.DeepCopy()
+	n.Finalizers = nil
+	require.Error(t, v.ValidateUpdate(ctx, r, n), "the policy forbids the record")
+
+	// the finalizer removal of the deleted record is accepted
+	now := metav1.Now()
+	old := r.DeepCopy()
+	old.DeletionTimestamp = &now
+	n = old.DeepCopy()
+	n.Finalizers = nil
+	assert.NoError(t, v.ValidateUpdate(ctx, old, n))
+}
+
+func TestRecordName(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  client.Object
+		want []string
+	}{
+		{
+			name: "dns record",
+			obj:  &DNSRecord{Spec: DNSRecordSpec{A: &ARecord{Name: "WWW.Example.org", Targets: []string{"10.0.0.1"}}}},
+			want: []string{"www.example.org."},
+		},
+		{
+			name: "cluster dns record",
+			obj:  &ClusterDNSRecord{Spec: DNSRecordSpec{CNAME: &CNAMERecord{Name: "www.example.org.", Target: "example.org."}}},
+			want: []string{"www.example.org."},
+		},
+		{
+			name: "raw record",
+			obj:  &DNSRecord{Spec: DNSRecordSpec{Raw: "Example.org. 60 IN TXT txt"}},
+			want: []string{"example.org."},
+		},
+		{
+			name: "invalid raw record",
+			obj:  &DNSRecord{Spec: DNSRecordSpec{Raw: "invalid"}},
+		},
+		{
+			name: "other object",
+			obj:  &corev1.Namespace{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, recordName(tt.obj))
+		})
+	}
+}
+
+func TestValidateCreateInvalidQuota(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{
+		QuotaRecordsAnnotation: "many",
+		QuotaMinTTLAnnotation:  "300",
+	}}}
+	v := newValidator(t, ns)
+	ctx := context.Background()
+
+	r := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"},
+		Spec:       DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Ttl: 300, Targets: []string{"10.0.0.1"}}},
+	}
+	assert.NoError(t, v.ValidateCreate(ctx, r), "the invalid records quota is ignored")
+	r.Spec.A.Ttl = 60
+	assert.Error(t, v.ValidateCreate(ctx, r), "the valid min ttl quota is enforced")
+}
@@ -17,7 +17,18 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net"
+	"net/url"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -30,15 +41,27 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"go.linka.cloud/k8s/dns/pkg/idn"
 	"go.linka.cloud/k8s/dns/pkg/ptr"
 )
 
+// RecreateAnnotation allows changing the type or the name of a record already created by its provider,
+// the provider's records are then re-created
+const RecreateAnnotation = "dns.linka.cloud/allow-recreate"
+
 // log is for logging in this package.
 var dnsrecordlog = logf.Log.WithName("dnsrecord-resource")
 
-func (r *DNSRecord) SetupWebhookWithManager(mgr ctrl.Manager) error {
+// SetupWebhookWithManager sets up the DNSRecord webhooks, the validation looks up the conflicting
+// records by name and requires the ClusterDNSRecord webhooks to be set up too
+func (r *DNSRecord) SetupWebhookWithManager(mgr ctrl.Manager, opts ...WebhookOption) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &DNSRecord{}, recordNameKey, recordName); err != nil {
+		return err
+	}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(newRecordDefaulter(mgr.GetClient(), opts...)).
+		WithValidator(&recordValidator{c: mgr.GetClient()}).
 		Complete()
 }
 
@@ -53,39 +76,45 @@ func (in *DNSRecord) Default() {
 	if in.Spec.Active == nil {
 		in.Spec.Active = ptr.Bool(true)
 	}
+	// the TTL is resolved from the namespace, the zone or the controller default by the webhook
+	if ttl := in.Spec.ttl(); ttl != nil && *ttl == 0 {
+		*ttl = DefaultTTL
+	}
+	// A records were used for IPv6 addresses before AAAA records were supported
+	if in.Spec.A.ipv6Only() {
+		in.Spec.AAAA = &AAAARecord{Name: in.Spec.A.Name, Class: in.Spec.A.Class, Ttl: in.Spec.A.Ttl, Targets: in.Spec.A.AllTargets()}
+		in.Spec.A = nil
+	}
 	switch {
 	case in.Spec.A != nil:
 		if in.Spec.A.Class == 0 {
 			in.Spec.A.Class = 1
 		}
-		if in.Spec.A.Ttl == 0 {
-			in.Spec.A.Ttl = 3600
-		}
 		in.Spec.A.Name = enforceFqdn(in.Spec.A.Name)
+		in.Spec.A.Targets = in.Spec.A.AllTargets()
+		in.Spec.A.Target = ""
+	case in.Spec.AAAA != nil:
+		if in.Spec.AAAA.Class == 0 {
+			in.Spec.AAAA.Class = 1
+		}
+		in.Spec.AAAA.Name = enforceFqdn(in.Spec.AAAA.Name)
+		in.Spec.AAAA.Targets = in.Spec.AAAA.AllTargets()
+		in.Spec.AAAA.Target = ""
 	case in.Spec.CNAME != nil:
 		if in.Spec.CNAME.Class == 0 {
 			in.Spec.CNAME.Class = 1
 		}
-		if in.Spec.CNAME.Ttl == 0 {
-			in.Spec.CNAME.Ttl = 3600
-		}
 		in.Spec.CNAME.Name = enforceFqdn(in.Spec.CNAME.Name)
 		in.Spec.CNAME.Target = enforceFqdn(in.Spec.CNAME.Target)
 	case in.Spec.TXT != nil:
 		if in.Spec.TXT.Class == 0 {
 			in.Spec.TXT.Class = 1
 		}
-		if in.Spec.TXT.Ttl == 0 {
-			in.Spec.TXT.Ttl = 3600
-		}
 		in.Spec.TXT.Name = enforceFqdn(in.Spec.TXT.Name)
 	case in.Spec.SRV != nil:
 		if in.Spec.SRV.Class == 0 {
 			in.Spec.SRV.Class = 1
 		}
-		if in.Spec.SRV.Ttl == 0 {
-			in.Spec.SRV.Ttl = 3600
-		}
 		if in.Spec.SRV.Weight == 0 {
 			in.Spec.SRV.Weight = 1
 		}
@@ -95,19 +124,83 @@ func (in *DNSRecord) Default() {
 		if in.Spec.MX.Class == 0 {
 			in.Spec.MX.Class = 1
 		}
-		if in.Spec.MX.Ttl == 0 {
-			in.Spec.MX.Ttl = 3600
-		}
 		if in.Spec.MX.Preference == 0 {
 			in.Spec.MX.Preference = 10
 		}
 		in.Spec.MX.Name = enforceFqdn(in.Spec.MX.Name)
 		in.Spec.MX.Target = enforceFqdn(in.Spec.MX.Target)
+	case in.Spec.CAA != nil:
+		if in.Spec.CAA.Class == 0 {
+			in.Spec.CAA.Class = 1
+		}
+		in.Spec.CAA.Name = enforceFqdn(in.Spec.CAA.Name)
+		in.Spec.CAA.Tag = strings.ToLower(in.Spec.CAA.Tag)
+	case in.Spec.NS != nil:
+		if in.Spec.NS.Class == 0 {
+			in.Spec.NS.Class = 1
+		}
+		in.Spec.NS.Name = enforceFqdn(in.Spec.NS.Name)
+		for i := range in.Spec.NS.Targets {
+			in.Spec.NS.Targets[i] = enforceFqdn(in.Spec.NS.Targets[i])
+		}
+	case in.Spec.PTR != nil:
+		if in.Spec.PTR.Class == 0 {
+			in.Spec.PTR.Class = 1
+		}
+		if net.ParseIP(in.Spec.PTR.Name) != nil {
+			in.Spec.PTR.Name, _ = dns.ReverseAddr(in.Spec.PTR.Name)
+		}
+		in.Spec.PTR.Name = enforceFqdn(in.Spec.PTR.Name)
+		in.Spec.PTR.Target = enforceFqdn(in.Spec.PTR.Target)
+	case in.Spec.SVCB != nil, in.Spec.HTTPS != nil:
+		r := in.Spec.SVCB
+		if r == nil {
+			r = in.Spec.HTTPS
+		}
+		if r.Class == 0 {
+			r.Class = 1
+		}
+		r.Name = enforceFqdn(r.Name)
+		if r.Target == "" {
+			r.Target = "."
+		}
+		r.Target = enforceFqdn(r.Target)
+	case in.Spec.TLSA != nil:
+		if in.Spec.TLSA.Class == 0 {
+			in.Spec.TLSA.Class = 1
+		}
+		in.Spec.TLSA.Name = enforceFqdn(in.Spec.TLSA.Name)
+		in.Spec.TLSA.Certificate = strings.ToLower(in.Spec.TLSA.Certificate)
+	case in.Spec.SPF != nil:
+		if in.Spec.SPF.Class == 0 {
+			in.Spec.SPF.Class = 1
+		}
+		in.Spec.SPF.Name = enforceFqdn(in.Spec.SPF.Name)
+	case in.Spec.DMARC != nil:
+		if in.Spec.DMARC.Class == 0 {
+			in.Spec.DMARC.Class = 1
+		}
+		in.Spec.DMARC.Name = enforceFqdn(in.Spec.DMARC.Name)
+		if !strings.HasPrefix(in.Spec.DMARC.Name, "_dmarc.") {
+			in.Spec.DMARC.Name = "_dmarc." + in.Spec.DMARC.Name
+		}
+	case in.Spec.DKIM != nil:
+		if in.Spec.DKIM.Class == 0 {
+			in.Spec.DKIM.Class = 1
+		}
+		if in.Spec.DKIM.KeyType == "" {
+			in.Spec.DKIM.KeyType = "rsa"
+		}
+		if in.Spec.DKIM.SecretName != "" && in.Spec.DKIM.SecretKey == "" {
+			in.Spec.DKIM.SecretKey = "dkim.key"
+		}
+		in.Spec.DKIM.Name = enforceFqdn(in.Spec.DKIM.Name)
 	}
 }
 
 func enforceFqdn(v string) string {
-	v = strings.ToLower(v)
+	// internationalized names are stored in their ASCII (punycode) form
+	v = idn.ToASCII(v)
 	d, err := publicsuffix.Domain(v)
 	if err != nil {
 		return v
@@ -132,49 +225,238 @@ func (r *DNSRecord) ValidateCreate() error {
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *DNSRecord) ValidateUpdate(old runtime.Object) error {
 	dnsrecordlog.Info("validate update", "name", r.Name)
-	return r.validate()
+	o, ok := old.(*DNSRecord)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a DNSRecord but got a %T", old))
+	}
+	// the finalizer removal must not be blocked by a spec which is no longer valid
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return r.invalid(append(r.Spec.validate(), r.validateImmutable(o)...))
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *DNSRecord) ValidateDelete() error {
-	dnsrecordlog.Info("validate delete", "name", r.Name)
-	return r.validate()
+	// the spec is not validated so that records which are no longer valid can be deleted
+	return nil
 }
 
 func (r *DNSRecord) validate() error {
-	var errs field.ErrorList
+	return r.invalid(r.Spec.validate())
+}
+
+func (r *DNSRecord) invalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: r.Kind}, r.Name, errs)
+}
+
+// validateImmutable forbids changing the type or the name of a record already created by its provider,
+// unless the RecreateAnnotation is set
+func (r *DNSRecord) validateImmutable(old *DNSRecord) (errs field.ErrorList) {
+	if old.Status.ID == "" && len(old.Status.IDs) == 0 {
+		return nil
+	}
+	if r.Annotations[RecreateAnnotation] == "true" {
+		return nil
+	}
+	typ, name := r.Spec.typeAndName()
+	oldTyp, oldName := old.Spec.typeAndName()
+	// the legacy IPv6 A records are converted to AAAA records by the mutating webhook
+	if typ == "AAAA" && old.Spec.A.ipv6Only() {
+		oldTyp = typ
+	}
+	// the SPF, DMARC and DKIM records are served as TXT records, switching between them does not change the provider's record
+	if renderedType(typ) != renderedType(oldTyp) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec"), fmt.Sprintf("the record type cannot be changed from %s to %s, set the %s annotation to \"true\" to re-create the record", oldTyp, typ, RecreateAnnotation)))
+	} else if !strings.EqualFold(dns.Fqdn(name), dns.Fqdn(oldName)) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec").Child(strings.ToLower(typ)).Child("name"), fmt.Sprintf("the record name cannot be changed from %s to %s, set the %s annotation to \"true\" to re-create the record", oldName, name, RecreateAnnotation)))
+	}
+	return errs
+}
+
+// renderedType returns the type of the resource record served for the spec's record type
+func renderedType(typ string) string {
+	switch typ {
+	case "SPF", "DMARC", "DKIM":
+		return "TXT"
+	}
+	return typ
+}
+
+// types returns the names of the record types set in the spec
+func (r *DNSRecordSpec) types() []string {
+	var out []string
+	for _, v := range []struct {
+		name string
+		set  bool
+	}{
+		{"a", r.A != nil},
+		{"aaaa", r.AAAA != nil},
+		{"cname", r.CNAME != nil},
+		{"txt", r.TXT != nil},
+		{"srv", r.SRV != nil},
+		{"mx", r.MX != nil},
+		{"caa", r.CAA != nil},
+		{"ns", r.NS != nil},
+		{"ptr", r.PTR != nil},
+		{"svcb", r.SVCB != nil},
+		{"https", r.HTTPS != nil},
+		{"tlsa", r.TLSA != nil},
+		{"spf", r.SPF != nil},
+		{"dmarc", r.DMARC != nil},
+		{"dkim", r.DKIM != nil},
+		{"raw", r.Raw != ""},
+	} {
+		if v.set {
+			out = append(out, v.name)
+		}
+	}
+	return out
+}
+
+// typeAndName returns the spec's record type and name
+func (r *DNSRecordSpec) typeAndName() (string, string) {
+	typ, name, _ := r.header()
+	return typ, name
+}
+
+// header returns the spec's record type, name and ttl, the raw records' ones are parsed from the record
+func (r *DNSRecordSpec) header() (string, string, uint32) {
 	switch {
-	case r.Spec.A != nil:
-		errs = append(errs, r.Spec.A.validate()...)
-	case r.Spec.CNAME != nil:
-		errs = append(errs, r.Spec.CNAME.validate()...)
-	case r.Spec.TXT != nil:
-		errs = append(errs, r.Spec.TXT.validate()...)
-	case r.Spec.SRV != nil:
-		errs = append(errs, r.Spec.SRV.validate()...)
-	case r.Spec.MX != nil:
-		errs = append(errs, r.Spec.MX.validate()...)
-	case r.Spec.Raw != "":
-		rr, err := dns.NewRR(r.Spec.Raw)
+	case r.A != nil:
+		return "A", r.A.Name, r.A.Ttl
+	case r.AAAA != nil:
+		return "AAAA", r.AAAA.Name, r.AAAA.Ttl
+	case r.CNAME != nil:
+		return "CNAME", r.CNAME.Name, r.CNAME.Ttl
+	case r.TXT != nil:
+		return "TXT", r.TXT.Name, r.TXT.Ttl
+	case r.SRV != nil:
+		return "SRV", r.SRV.Name, r.SRV.Ttl
+	case r.MX != nil:
+		return "MX", r.MX.Name, r.MX.Ttl
+	case r.CAA != nil:
+		return "CAA", r.CAA.Name, r.CAA.Ttl
+	case r.NS != nil:
+		return "NS", r.NS.Name, r.NS.Ttl
+	case r.PTR != nil:
+		return "PTR", r.PTR.Name, r.PTR.Ttl
+	case r.SVCB != nil:
+		return "SVCB", r.SVCB.Name, r.SVCB.Ttl
+	case r.HTTPS != nil:
+		return "HTTPS", r.HTTPS.Name, r.HTTPS.Ttl
+	case r.TLSA != nil:
+		return "TLSA", r.TLSA.Name, r.TLSA.Ttl
+	case r.SPF != nil:
+		return "SPF", r.SPF.Name, r.SPF.Ttl
+	case r.DMARC != nil:
+		return "DMARC", r.DMARC.Name, r.DMARC.Ttl
+	case r.DKIM != nil:
+		return "DKIM", r.DKIM.Name, r.DKIM.Ttl
+	case r.Raw != "":
+		rr, err := dns.NewRR(r.Raw)
 		if err != nil || rr == nil {
-			errs = append(errs, field.Invalid(field.NewPath("spec").Child("raw"), r.Spec.Raw, "failed to parse raw record"))
+			return "RAW", "", 0
 		}
-	default:
-		errs = append(errs, field.Invalid(field.NewPath("spec"), r.Spec, "neither a A, CNAME, TXT, SRV, MX or RAW record"))
+		return dns.TypeToString[rr.Header().Rrtype], rr.Header().Name, rr.Header().Ttl
 	}
-	if len(errs) == 0 {
-		return nil
+	return "", "", 0
+}
+
+func (r *DNSRecordSpec) validate() (errs field.ErrorList) {
+	if types := r.types(); len(types) > 1 {
+		return field.ErrorList{field.Invalid(field.NewPath("spec"), strings.Join(types, ", "), "only one record type can be set")}
 	}
-	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: r.Kind}, r.Name, errs)
+	switch {
+	case r.A != nil:
+		errs = append(errs, r.A.validate()...)
+	case r.AAAA != nil:
+		errs = append(errs, r.AAAA.validate()...)
+	case r.CNAME != nil:
+		errs = append(errs, r.CNAME.validate()...)
+	case r.TXT != nil:
+		errs = append(errs, r.TXT.validate()...)
+	case r.SRV != nil:
+		errs = append(errs, r.SRV.validate()...)
+	case r.MX != nil:
+		errs = append(errs, r.MX.validate()...)
+	case r.CAA != nil:
+		errs = append(errs, r.CAA.validate()...)
+	case r.NS != nil:
+		errs = append(errs, r.NS.validate()...)
+	case r.PTR != nil:
+		errs = append(errs, r.PTR.validate()...)
+	case r.SVCB != nil:
+		errs = append(errs, r.SVCB.validate(field.NewPath("spec").Child("svcb"))...)
+	case r.HTTPS != nil:
+		errs = append(errs, r.HTTPS.validate(field.NewPath("spec").Child("https"))...)
+	case r.TLSA != nil:
+		errs = append(errs, r.TLSA.validate()...)
+	case r.SPF != nil:
+		errs = append(errs, r.SPF.validate()...)
+	case r.DMARC != nil:
+		errs = append(errs, r.DMARC.validate()...)
+	case r.DKIM != nil:
+		errs = append(errs, r.DKIM.validate()...)
+	case r.Raw != "":
+		rr, err := dns.NewRR(r.Raw)
+		if err != nil || rr == nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("raw"), r.Raw, "failed to parse raw record"))
+		}
+	default:
+		errs = append(errs, field.Invalid(field.NewPath("spec"), *r, "neither a A, AAAA, CNAME, TXT, SRV, MX, CAA, NS, PTR, SVCB, HTTPS, TLSA, SPF, DMARC, DKIM or RAW record"))
+	}
+	return errs
 }
 
 func (r *ARecord) validate() (errs field.ErrorList) {
 	if !strings.HasSuffix(r.Name, ".") {
 		errs = append(errs, field.Invalid(field.NewPath("spec").Child("a").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
 	}
-	ip := net.ParseIP(r.Target)
-	if ip == nil || r.Target == "" {
-		errs = append(errs, field.Invalid(field.NewPath("spec").Child("a").Child("target"), r.Target, "A Record: target must be a valid ip address"))
+	if r.Target != "" {
+		if ip := net.ParseIP(r.Target); ip == nil || ip.To4() == nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("a").Child("target"), r.Target, "A Record: target must be a valid ipv4 address"))
+		}
+	}
+	errs = append(errs, validateTargets(field.NewPath("spec").Child("a").Child("targets"), r.AllTargets(), r.Targets, func(ip net.IP) bool {
+		return ip.To4() != nil
+	}, "A Record: target must be a valid ipv4 address")...)
+	return
+}
+func (r *AAAARecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("aaaa").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if r.Target != "" {
+		if ip := net.ParseIP(r.Target); ip == nil || ip.To4() != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("aaaa").Child("target"), r.Target, "AAAA Record: target must be a valid ipv6 address"))
+		}
+	}
+	errs = append(errs, validateTargets(field.NewPath("spec").Child("aaaa").Child("targets"), r.AllTargets(), r.Targets, func(ip net.IP) bool {
+		return ip.To4() == nil
+	}, "AAAA Record: target must be a valid ipv6 address")...)
+	return
+}
+
+// validateTargets validates the addresses of an A or AAAA record
+func validateTargets(path *field.Path, all, targets []string, family func(ip net.IP) bool, msg string) (errs field.ErrorList) {
+	if len(all) == 0 {
+		errs = append(errs, field.Required(path, "at least one target is required"))
+	}
+	seen := make(map[string]struct{})
+	for i, v := range targets {
+		ip := net.ParseIP(v)
+		if ip == nil || !family(ip) {
+			errs = append(errs, field.Invalid(path.Index(i), v, msg))
+			continue
+		}
+		if _, ok := seen[ip.String()]; ok {
+			errs = append(errs, field.Duplicate(path.Index(i), v))
+		}
+		seen[ip.String()] = struct{}{}
 	}
 	return
 }
@@ -223,3 +505,323 @@ func (r *MXRecord) validate() (errs field.ErrorList) {
 	}
 	return
 }
+func (r *NSRecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("ns").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if len(r.Targets) == 0 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("ns").Child("targets"), r.Targets, "NS Record: targets cannot be empty"))
+	}
+	for i, v := range r.Targets {
+		if v == "" {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("ns").Child("targets").Index(i), v, "NS Record: target is required"))
+		}
+		if !strings.HasSuffix(v, ".") {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("ns").Child("targets").Index(i), v, "target must be an absolute dns name (should ends with a dot)"))
+		}
+	}
+	return
+}
+func (r *PTRRecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("ptr").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if r.Target == "" {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("ptr").Child("target"), r.Target, "PTR Record: target is required"))
+	}
+	if !strings.HasSuffix(r.Target, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("ptr").Child("target"), r.Target, "target must be an absolute dns name (should ends with a dot)"))
+	}
+	return
+}
+func (r *CAARecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("caa").Child("name"), r.Name, "must be an absolute dns name (should ends with a dot)"))
+	}
+	// only the issuer critical flag is defined, see RFC 8659 section 4.1
+	if r.Flag != 0 && r.Flag != 128 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("caa").Child("flag"), int(r.Flag), "CAA Record: flag must be either 0 or 128"))
+	}
+	switch r.Tag {
+	case "issue", "issuewild":
+		if err := validateCAAIssuer(r.Value); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("caa").Child("value"), r.Value, "CAA Record: "+err.Error()))
+		}
+	case "iodef":
+		u, err := url.Parse(r.Value)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("caa").Child("value"), r.Value, "CAA Record: iodef value must be a mailto, http or https url"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("caa").Child("tag"), r.Tag, []string{"issue", "issuewild", "iodef"}))
+	}
+	return
+}
+
+// validate validates a SVCB or HTTPS record, see RFC 9460
+func (r *SVCBRecord) validate(path *field.Path) (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(path.Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if r.Target == "" {
+		errs = append(errs, field.Invalid(path.Child("target"), r.Target, "SVCB Record: target is required"))
+	}
+	if !strings.HasSuffix(r.Target, ".") {
+		errs = append(errs, field.Invalid(path.Child("target"), r.Target, "target must be an absolute dns name (should ends with a dot)"))
+	}
+	if r.Priority == 0 {
+		if len(r.Alpn) != 0 || r.NoDefaultAlpn || r.Port != 0 || len(r.IPv4Hint) != 0 || len(r.IPv6Hint) != 0 || r.ECH != "" {
+			errs = append(errs, field.Invalid(path.Child("priority"), int(r.Priority), "SVCB Record: parameters are not allowed in alias mode (priority 0)"))
+		}
+		return
+	}
+	seen := make(map[string]struct{})
+	for i, v := range r.Alpn {
+		if v == "" || len(v) > 255 {
+			errs = append(errs, field.Invalid(path.Child("alpn").Index(i), v, "SVCB Record: alpn id must be between 1 and 255 characters"))
+		}
+		if _, ok := seen[v]; ok {
+			errs = append(errs, field.Duplicate(path.Child("alpn").Index(i), v))
+		}
+		seen[v] = struct{}{}
+	}
+	if r.NoDefaultAlpn && len(r.Alpn) == 0 {
+		errs = append(errs, field.Invalid(path.Child("noDefaultAlpn"), r.NoDefaultAlpn, "SVCB Record: alpn is required when noDefaultAlpn is set"))
+	}
+	for i, v := range r.IPv4Hint {
+		if ip := net.ParseIP(v); ip == nil || ip.To4() == nil {
+			errs = append(errs, field.Invalid(path.Child("ipv4hint").Index(i), v, "SVCB Record: ipv4hint must be a valid ipv4 address"))
+		}
+	}
+	for i, v := range r.IPv6Hint {
+		if ip := net.ParseIP(v); ip == nil || ip.To4() != nil {
+			errs = append(errs, field.Invalid(path.Child("ipv6hint").Index(i), v, "SVCB Record: ipv6hint must be a valid ipv6 address"))
+		}
+	}
+	if r.ECH != "" {
+		if b, err := base64.StdEncoding.DecodeString(r.ECH); err != nil || len(b) == 0 {
+			errs = append(errs, field.Invalid(path.Child("ech"), r.ECH, "SVCB Record: ech must be a base64 encoded ECHConfigList"))
+		}
+	}
+	return
+}
+func (r *TLSARecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if r.Usage > 3 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("usage"), int(r.Usage), "TLSA Record: usage must be between 0 and 3"))
+	}
+	if r.Selector > 1 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("selector"), int(r.Selector), "TLSA Record: selector must be either 0 or 1"))
+	}
+	if r.MatchingType > 2 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("matchingType"), int(r.MatchingType), "TLSA Record: matching type must be between 0 and 2"))
+	}
+	if r.Certificate == "" && r.SecretName == "" {
+		errs = append(errs, field.Required(field.NewPath("spec").Child("tlsa").Child("certificate"), "TLSA Record: either certificate or secretName is required"))
+	}
+	if r.Certificate == "" {
+		return
+	}
+	b, err := hex.DecodeString(r.Certificate)
+	if err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("certificate"), r.Certificate, "TLSA Record: certificate must be hex encoded"))
+		return
+	}
+	if (r.MatchingType == 1 && len(b) != sha256.Size) || (r.MatchingType == 2 && len(b) != sha512.Size) {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("tlsa").Child("certificate"), r.Certificate, "TLSA Record: certificate length does not match the matching type"))
+	}
+	return
+}
+func (r *SPFRecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("spf").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if len(r.Mechanisms) == 0 {
+		errs = append(errs, field.Required(field.NewPath("spec").Child("spf").Child("mechanisms"), "SPF Record: at least one mechanism is required"))
+	}
+	lookups := 0
+	for i, v := range r.Mechanisms {
+		lookup, err := validateSPFTerm(v)
+		if err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("spf").Child("mechanisms").Index(i), v, "SPF Record: "+err.Error()))
+			continue
+		}
+		if lookup {
+			lookups++
+		}
+		if strings.TrimLeft(v, "+-~?") == "all" && i != len(r.Mechanisms)-1 {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("spf").Child("mechanisms").Index(i), v, "SPF Record: all must be the last mechanism"))
+		}
+	}
+	// see RFC 7208 section 4.6.4
+	if lookups > 10 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("spf").Child("mechanisms"), lookups, "SPF Record: more than 10 mechanisms require a dns lookup"))
+	}
+	return
+}
+
+// validateSPFTerm validates a SPF mechanism or modifier and returns whether it requires a dns lookup
+func validateSPFTerm(v string) (bool, error) {
+	if v == "" || strings.ContainsAny(v, " \t\"") {
+		return false, fmt.Errorf("invalid term: %q", v)
+	}
+	if i := strings.Index(v, "="); i > 0 && !strings.ContainsAny(v[:i], ":/") {
+		switch name, arg := strings.ToLower(v[:i]), v[i+1:]; {
+		case arg == "":
+			return false, fmt.Errorf("%s modifier: missing value", name)
+		case name == "redirect":
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+	v = strings.TrimLeft(v, "+-~?")
+	name, arg := v, ""
+	if i := strings.IndexAny(v, ":/"); i >= 0 {
+		name, arg = v[:i], v[i:]
+	}
+	switch strings.ToLower(name) {
+	case "all":
+		if arg != "" {
+			return false, errors.New("all mechanism does not take any argument")
+		}
+		return false, nil
+	case "include", "exists":
+		if len(arg) < 2 || arg[0] != ':' {
+			return false, fmt.Errorf("%s mechanism: a domain is required", name)
+		}
+		return true, nil
+	case "a", "mx", "ptr":
+		return true, nil
+	case "ip4", "ip6":
+		if len(arg) < 2 || arg[0] != ':' {
+			return false, fmt.Errorf("%s mechanism: an address is required", name)
+		}
+		ip := net.ParseIP(arg[1:])
+		if ip == nil {
+			var err error
+			if ip, _, err = net.ParseCIDR(arg[1:]); err != nil {
+				return false, fmt.Errorf("%s mechanism: invalid address: %s", name, arg[1:])
+			}
+		}
+		if (name == "ip4") != (ip.To4() != nil) {
+			return false, fmt.Errorf("%s mechanism: invalid address family: %s", name, arg[1:])
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown mechanism: %s", name)
+	}
+}
+
+func (r *DMARCRecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if !strings.HasPrefix(r.Name, "_dmarc.") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("name"), r.Name, "DMARC Record: name must start with _dmarc."))
+	}
+	policies := []string{"none", "quarantine", "reject"}
+	if !stringIn(r.Policy, policies) {
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("dmarc").Child("policy"), r.Policy, policies))
+	}
+	if r.SubdomainPolicy != "" && !stringIn(r.SubdomainPolicy, policies) {
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("dmarc").Child("subdomainPolicy"), r.SubdomainPolicy, policies))
+	}
+	if r.Percent != nil && *r.Percent > 100 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("percent"), int(*r.Percent), "DMARC Record: percent must be between 0 and 100"))
+	}
+	for i, v := range r.ReportAggregate {
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || strings.ContainsAny(v, ",;!") {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("rua").Index(i), v, "DMARC Record: report address must be an uri, e.g. mailto:dmarc@example.org"))
+		}
+	}
+	for i, v := range r.ReportFailure {
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || strings.ContainsAny(v, ",;!") {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("ruf").Index(i), v, "DMARC Record: report address must be an uri, e.g. mailto:dmarc@example.org"))
+		}
+	}
+	if r.AlignmentDKIM != "" && r.AlignmentDKIM != "r" && r.AlignmentDKIM != "s" {
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("dmarc").Child("adkim"), r.AlignmentDKIM, []string{"r", "s"}))
+	}
+	if r.AlignmentSPF != "" && r.AlignmentSPF != "r" && r.AlignmentSPF != "s" {
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("dmarc").Child("aspf"), r.AlignmentSPF, []string{"r", "s"}))
+	}
+	if r.FailureOptions != "" {
+		for _, v := range strings.Split(r.FailureOptions, ":") {
+			if !stringIn(v, []string{"0", "1", "d", "s"}) {
+				errs = append(errs, field.Invalid(field.NewPath("spec").Child("dmarc").Child("fo"), r.FailureOptions, "DMARC Record: failure options must be a colon separated list of 0, 1, d or s"))
+				break
+			}
+		}
+	}
+	return
+}
+
+func (r *DKIMRecord) validate() (errs field.ErrorList) {
+	if !strings.HasSuffix(r.Name, ".") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("name"), r.Name, "name must be an absolute dns name (should ends with a dot)"))
+	}
+	if !strings.Contains(r.Name, "._domainkey.") {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("name"), r.Name, "DKIM Record: name must be a selector's name, e.g. mail._domainkey.example.org."))
+	}
+	if r.KeyType != "rsa" && r.KeyType != "ed25519" {
+		errs = append(errs, field.NotSupported(field.NewPath("spec").Child("dkim").Child("keyType"), r.KeyType, []string{"rsa", "ed25519"}))
+	}
+	if r.PublicKey == "" && r.SecretName == "" {
+		errs = append(errs, field.Required(field.NewPath("spec").Child("dkim").Child("publicKey"), "DKIM Record: either publicKey or secretName is required"))
+	}
+	if r.PublicKey == "" {
+		return
+	}
+	b, err := base64.StdEncoding.DecodeString(r.PublicKey)
+	if err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("publicKey"), r.PublicKey, "DKIM Record: public key must be base64 encoded"))
+		return
+	}
+	switch r.KeyType {
+	case "rsa":
+		if k, err := x509.ParsePKIXPublicKey(b); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("publicKey"), r.PublicKey, "DKIM Record: invalid rsa public key"))
+		} else if _, ok := k.(*rsa.PublicKey); !ok {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("publicKey"), r.PublicKey, "DKIM Record: public key is not a rsa key"))
+		}
+	case "ed25519":
+		// RFC 8463 uses the raw key instead of the SubjectPublicKeyInfo
+		if len(b) != ed25519.PublicKeySize {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("dkim").Child("publicKey"), r.PublicKey, "DKIM Record: invalid ed25519 public key"))
+		}
+	}
+	return
+}
+
+func stringIn(v string, values []string) bool {
+	for _, vv := range values {
+		if v == vv {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCAAIssuer validates an issue / issuewild value, e.g. "letsencrypt.org; validationmethods=dns-01" or ";"
+func validateCAAIssuer(v string) error {
+	parts := strings.Split(v, ";")
+	if d := strings.TrimSpace(parts[0]); d != "" {
+		if _, ok := dns.IsDomainName(d); !ok || strings.HasSuffix(d, ".") {
+			return fmt.Errorf("invalid issuer domain name: %s", d)
+		}
+	}
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("invalid issuer parameter: %s (expected key=value)", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefaultIPv6ARecord(t *testing.T) {
+	tests := []struct {
+		name string
+		spec DNSRecordSpec
+		want DNSRecordSpec
+	}{
+		{
+			name: "ipv6 only",
+			spec: DNSRecordSpec{A: &ARecord{Name: "example.org", Ttl: 60, Target: "2001:db8::1", Targets: []string{"2001:db8::2"}}},
+			want: DNSRecordSpec{AAAA: &AAAARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"2001:db8::1", "2001:db8::2"}}},
+		},
+		{
+			name: "ipv4",
+			spec: DNSRecordSpec{A: &ARecord{Name: "example.org", Ttl: 60, Targets: []string{"10.0.0.1"}}},
+			want: DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"10.0.0.1"}}},
+		},
+		{
+			name: "mixed families are kept for the validation to reject them",
+			spec: DNSRecordSpec{A: &ARecord{Name: "example.org", Ttl: 60, Targets: []string{"10.0.0.1", "2001:db8::1"}}},
+			want: DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"10.0.0.1", "2001:db8::1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &DNSRecord{Spec: tt.spec}
+			r.Default()
+			r.Spec.Active = nil
+			assert.Equal(t, tt.want, r.Spec)
+		})
+	}
+}
+
+func TestUpdateIPv6ARecord(t *testing.T) {
+	old := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec:       DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"2001:db8::1"}}},
+		Status:     DNSRecordStatus{ID: "id"},
+	}
+	r := old.DeepCopy()
+	r.Default()
+	require.NotNil(t, r.Spec.AAAA)
+	assert.NoError(t, r.ValidateUpdate(old))
+}
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec DNSRecordSpec
+		err  string
+	}{
+		{
+			name: "single type",
+			spec: DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}},
+		},
+		{
+			name: "two types",
+			spec: DNSRecordSpec{
+				A:   &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}},
+				TXT: &TXTRecord{Name: "example.org.", Class: 1, Targets: []string{"txt"}},
+			},
+			err: `spec: Invalid value: "a, txt": only one record type can be set`,
+		},
+		{
+			name: "type and raw",
+			spec: DNSRecordSpec{
+				CNAME: &CNAMERecord{Name: "www.example.org.", Class: 1, Target: "example.org."},
+				Raw:   "www.example.org. 60 IN CNAME example.org.",
+			},
+			err: `spec: Invalid value: "cname, raw": only one record type can be set`,
+		},
+		{
+			name: "caa issue",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "issue", Value: "letsencrypt.org; validationmethods=dns-01"}},
+		},
+		{
+			name: "caa issue forbidding issuance",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "issuewild", Value: ";"}},
+		},
+		{
+			name: "caa critical flag",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Flag: 128, Tag: "issue", Value: "letsencrypt.org"}},
+		},
+		{
+			name: "caa invalid flag",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Flag: 1, Tag: "issue", Value: "letsencrypt.org"}},
+			err:  `spec.caa.flag: Invalid value: 1: CAA Record: flag must be either 0 or 128`,
+		},
+		{
+			name: "caa unsupported tag",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "issuemail", Value: "letsencrypt.org"}},
+			err:  `spec.caa.tag: Unsupported value: "issuemail": supported values: "issue", "issuewild", "iodef"`,
+		},
+		{
+			name: "caa invalid issuer",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "issue", Value: "letsencrypt.org."}},
+			err:  `spec.caa.value: Invalid value: "letsencrypt.org.": CAA Record: invalid issuer domain name: letsencrypt.org.`,
+		},
+		{
+			name: "caa invalid issuer parameter",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "issue", Value: "letsencrypt.org; accounturi"}},
+			err:  `spec.caa.value: Invalid value: "letsencrypt.org; accounturi": CAA Record: invalid issuer parameter: accounturi (expected key=value)`,
+		},
+		{
+			name: "caa iodef",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "iodef", Value: "mailto:security@example.org"}},
+		},
+		{
+			name: "caa invalid iodef",
+			spec: DNSRecordSpec{CAA: &CAARecord{Name: "example.org.", Class: 1, Tag: "iodef", Value: "ftp://example.org"}},
+			err:  `spec.caa.value: Invalid value: "ftp://example.org": CAA Record: iodef value must be a mailto, http or https url`,
+		},
+		{
+			name: "svcb alias",
+			spec: DNSRecordSpec{SVCB: &SVCBRecord{Name: "_8443._foo.example.org.", Class: 1, Target: "svc.example.org."}},
+		},
+		{
+			name: "svcb alias with parameters",
+			spec: DNSRecordSpec{SVCB: &SVCBRecord{Name: "_8443._foo.example.org.", Class: 1, Target: "svc.example.org.", Port: 8443}},
+			err:  `spec.svcb.priority: Invalid value: 0: SVCB Record: parameters are not allowed in alias mode (priority 0)`,
+		},
+		{
+			name: "svcb relative target",
+			spec: DNSRecordSpec{SVCB: &SVCBRecord{Name: "_8443._foo.example.org.", Class: 1, Priority: 1, Target: "svc.example.org"}},
+			err:  `spec.svcb.target: Invalid value: "svc.example.org": target must be an absolute dns name (should ends with a dot)`,
+		},
+		{
+			name: "https service",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{
+				Name:     "example.org.",
+				Class:    1,
+				Priority: 1,
+				Target:   ".",
+				Alpn:     []string{"h2", "h3"},
+				Port:     8443,
+				IPv4Hint: []string{"192.0.2.1"},
+				IPv6Hint: []string{"2001:db8::1"},
+				ECH:      "AEX+DQBBpQAgACB/",
+			}},
+		},
+		{
+			name: "https duplicate alpn",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", Alpn: []string{"h2", "h2"}}},
+			err:  `spec.https.alpn[1]: Duplicate value: "h2"`,
+		},
+		{
+			name: "https no default alpn without alpn",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", NoDefaultAlpn: true}},
+			err:  `spec.https.noDefaultAlpn: Invalid value: true: SVCB Record: alpn is required when noDefaultAlpn is set`,
+		},
+		{
+			name: "https ipv6 in ipv4hint",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", IPv4Hint: []string{"2001:db8::1"}}},
+			err:  `spec.https.ipv4hint[0]: Invalid value: "2001:db8::1": SVCB Record: ipv4hint must be a valid ipv4 address`,
+		},
+		{
+			name: "https ipv4 in ipv6hint",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", IPv6Hint: []string{"192.0.2.1"}}},
+			err:  `spec.https.ipv6hint[0]: Invalid value: "192.0.2.1": SVCB Record: ipv6hint must be a valid ipv6 address`,
+		},
+		{
+			name: "https invalid ech",
+			spec: DNSRecordSpec{HTTPS: &SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", ECH: "not base64"}},
+			err:  `spec.https.ech: Invalid value: "not base64": SVCB Record: ech must be a base64 encoded ECHConfigList`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.spec.validate()
+			if tt.err == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.err, errs[0].Error())
+		})
+	}
+}
+
+func TestValidateImmutable(t *testing.T) {
+	a := DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}}
+	txt := DNSRecordSpec{TXT: &TXTRecord{Name: "example.org.", Class: 1, Targets: []string{"v=spf1 -all"}}}
+	spf := DNSRecordSpec{SPF: &SPFRecord{Name: "example.org.", Class: 1, Mechanisms: []string{"-all"}}}
+	tests := []struct {
+		name        string
+		old         DNSRecordSpec
+		new         DNSRecordSpec
+		created     bool
+		annotations map[string]string
+		err         string
+	}{
+		{
+			name: "not created",
+			old:  a,
+			new:  txt,
+		},
+		{
+			name:    "same type and name",
+			old:     a,
+			new:     DNSRecordSpec{A: &ARecord{Name: "EXAMPLE.org", Class: 1, Targets: []string{"10.0.0.2"}}},
+			created: true,
+		},
+		{
+			name:    "type change",
+			old:     a,
+			new:     txt,
+			created: true,
+			err:     `spec: Forbidden: the record type cannot be changed from A to TXT, set the dns.linka.cloud/allow-recreate annotation to "true" to re-create the record`,
+		},
+		{
+			name:    "name change",
+			old:     a,
+			new:     DNSRecordSpec{A: &ARecord{Name: "www.example.org.", Class: 1, Targets: []string{"10.0.0.1"}}},
+			created: true,
+			err:     `spec.a.name: Forbidden: the record name cannot be changed from example.org. to www.example.org., set the dns.linka.cloud/allow-recreate annotation to "true" to re-create the record`,
+		},
+		{
+			name:        "recreate annotation",
+			old:         a,
+			new:         txt,
+			created:     true,
+			annotations: map[string]string{RecreateAnnotation: "true"},
+		},
+		{
+			name:    "spf to txt",
+			old:     spf,
+			new:     txt,
+			created: true,
+		},
+		{
+			name:    "txt to spf",
+			old:     txt,
+			new:     spf,
+			created: true,
+		},
+		{
+			name:    "spf name change",
+			old:     spf,
+			new:     DNSRecordSpec{TXT: &TXTRecord{Name: "www.example.org.", Class: 1, Targets: []string{"v=spf1 -all"}}},
+			created: true,
+			err:     `spec.txt.name: Forbidden: the record name cannot be changed from example.org. to www.example.org., set the dns.linka.cloud/allow-recreate annotation to "true" to re-create the record`,
+		},
+		{
+			name:    "raw with the same type",
+			old:     a,
+			new:     DNSRecordSpec{Raw: "example.org. 60 IN A 10.0.0.1"},
+			created: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &DNSRecord{Spec: tt.old}
+			if tt.created {
+				old.Status.IDs = []string{"id"}
+			}
+			r := &DNSRecord{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}, Spec: tt.new}
+			errs := r.validateImmutable(old)
+			if tt.err == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.err, errs[0].Error())
+		})
+	}
+}
+
+func TestValidateUpdateDeleted(t *testing.T) {
+	old := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Finalizers: []string{"dns.linka.cloud/finalizer"}},
+		Spec:       DNSRecordSpec{A: &ARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}},
+		Status:     DNSRecordStatus{IDs: []string{"id"}},
+	}
+	r := old.DeepCopy()
+	// a spec which would no longer be accepted
+	r.Spec.TXT = &TXTRecord{Name: "example.org.", Class: 1, Targets: []string{"txt"}}
+	r.Finalizers = nil
+	require.Error(t, r.ValidateUpdate(old))
+	now := metav1.Now()
+	r.DeletionTimestamp = &now
+	assert.NoError(t, r.ValidateUpdate(old))
+}
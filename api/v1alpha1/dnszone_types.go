@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DNSZoneSpec defines the desired state of DNSZone
+type DNSZoneSpec struct {
+	// Origin is the zone's name, e.g. example.org.
+	Origin string `json:"origin"`
+	// TTL is the zone's SOA and NS records TTL
+	// +optional
+	TTL uint32 `json:"ttl,omitempty"`
+	// DefaultTTL is the TTL of the zone's records not setting one,
+	// when their namespace does not define a default TTL
+	// +optional
+	DefaultTTL uint32 `json:"defaultTTL,omitempty"`
+	// +optional
+	SOA SOA `json:"soa,omitempty"`
+	// Nameservers are the zone's authoritative name servers,
+	// the CoreDNS provider defaults to ns0.dns.<origin> pointing to its external address
+	// +optional
+	Nameservers []string `json:"nameservers,omitempty"`
+}
+
+type SOA struct {
+	// Mbox is the zone's administrator mailbox, e.g. hostmaster.example.org.
+	// +optional
+	Mbox string `json:"mbox,omitempty"`
+	// +optional
+	Refresh uint32 `json:"refresh,omitempty"`
+	// +optional
+	Retry uint32 `json:"retry,omitempty"`
+	// +optional
+	Expire uint32 `json:"expire,omitempty"`
+	// Minimum is the negative responses TTL
+	// +optional
+	Minimum uint32 `json:"minimum,omitempty"`
+}
+
+// DNSZoneStatus defines the observed state of DNSZone
+type DNSZoneStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=dnszones,scope=Cluster,shortName=zones;zone
+// +kubebuilder:printcolumn:name="Origin",type=string,JSONPath=`.spec.origin`
+// +kubebuilder:printcolumn:name="Nameservers",type=string,JSONPath=`.spec.nameservers`
+
+// DNSZone is the Schema for the dnszones API
+type DNSZone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSZoneSpec   `json:"spec,omitempty"`
+	Status DNSZoneStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DNSZoneList contains a list of DNSZone
+type DNSZoneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSZone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSZone{}, &DNSZoneList{})
+}
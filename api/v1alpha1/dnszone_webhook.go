@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"go.linka.cloud/k8s/dns/pkg/idn"
+)
+
+const (
+	DefaultZoneTTL = 3600
+	DefaultRefresh = 7200
+	DefaultRetry   = 1800
+	DefaultExpire  = 86400
+	DefaultMinimum = 5
+)
+
+// log is for logging in this package.
+var dnszonelog = logf.Log.WithName("dnszone-resource")
+
+func (in *DNSZone) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(in).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dns-linka-cloud-v1alpha1-dnszone,mutating=true,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=dnszones,verbs=create;update,versions=v1alpha1,name=mdnszone.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &DNSZone{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (in *DNSZone) Default() {
+	in.Spec.Origin = fqdn(in.Spec.Origin)
+	if in.Spec.TTL == 0 {
+		in.Spec.TTL = DefaultZoneTTL
+	}
+	if in.Spec.SOA.Mbox == "" && in.Spec.Origin != "" {
+		in.Spec.SOA.Mbox = "hostmaster." + in.Spec.Origin
+	}
+	in.Spec.SOA.Mbox = fqdn(in.Spec.SOA.Mbox)
+	if in.Spec.SOA.Refresh == 0 {
+		in.Spec.SOA.Refresh = DefaultRefresh
+	}
+	if in.Spec.SOA.Retry == 0 {
+		in.Spec.SOA.Retry = DefaultRetry
+	}
+	if in.Spec.SOA.Expire == 0 {
+		in.Spec.SOA.Expire = DefaultExpire
+	}
+	if in.Spec.SOA.Minimum == 0 {
+		in.Spec.SOA.Minimum = DefaultMinimum
+	}
+	for i := range in.Spec.Nameservers {
+		in.Spec.Nameservers[i] = fqdn(in.Spec.Nameservers[i])
+	}
+}
+
+// fqdn returns the name's ASCII form as an absolute name,
+// unlike enforceFqdn, zones may be public suffixes or private names, e.g. cluster.local
+func fqdn(v string) string {
+	v = idn.ToASCII(v)
+	if v == "" || strings.HasSuffix(v, ".") {
+		return v
+	}
+	return v + "."
+}
+
+// +kubebuilder:webhook:path=/validate-dns-linka-cloud-v1alpha1-dnszone,mutating=false,failurePolicy=fail,sideEffects=None,groups=dns.linka.cloud,resources=dnszones,verbs=create;update,versions=v1alpha1,name=vdnszone.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &DNSZone{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSZone) ValidateCreate() error {
+	dnszonelog.Info("validate create", "name", in.Name)
+	return in.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSZone) ValidateUpdate(old runtime.Object) error {
+	dnszonelog.Info("validate update", "name", in.Name)
+	return in.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *DNSZone) ValidateDelete() error {
+	return nil
+}
+
+func (in *DNSZone) validate() error {
+	var errs field.ErrorList
+	if in.Spec.Origin == "" {
+		errs = append(errs, field.Required(field.NewPath("spec").Child("origin"), "origin is required"))
+	} else if _, ok := dns.IsDomainName(in.Spec.Origin); !ok || in.Spec.Origin == "." {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("origin"), in.Spec.Origin, "origin must be a valid dns name"))
+	}
+	if _, ok := dns.IsDomainName(in.Spec.SOA.Mbox); !ok {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("soa").Child("mbox"), in.Spec.SOA.Mbox, "mbox must be a valid dns name, e.g. hostmaster.example.org."))
+	}
+	for i, v := range in.Spec.Nameservers {
+		if _, ok := dns.IsDomainName(v); !ok || v == "." {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("nameservers").Index(i), v, "nameserver must be a valid dns name"))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "DNSZone"}, in.Name, errs)
+}
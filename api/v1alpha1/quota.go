@@ -0,0 +1,136 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// QuotaRecordsAnnotation is the namespace annotation limiting its number of DNSRecords
+	QuotaRecordsAnnotation = "dns.linka.cloud/quota-records"
+	// QuotaTypesAnnotation is the namespace annotation limiting its number of DNSRecords per type, e.g. A=20,CNAME=10
+	QuotaTypesAnnotation = "dns.linka.cloud/quota-types"
+	// QuotaMinTTLAnnotation is the namespace annotation setting its DNSRecords minimum TTL
+	QuotaMinTTLAnnotation = "dns.linka.cloud/quota-min-ttl"
+)
+
+// Quota is a namespace's DNSRecords quota
+// +kubebuilder:object:generate=false
+type Quota struct {
+	// Records is the maximum number of records, 0 means unlimited
+	Records int
+	// Types is the maximum number of records per type
+	Types map[string]int
+	// MinTTL is the records minimum TTL
+	MinTTL uint32
+}
+
+// QuotaFor returns the quota defined by the namespace's annotations, nil if none is defined.
+// The invalid annotations are ignored: the returned quota is defined by the valid ones
+// and the error reports the invalid ones.
+func QuotaFor(ns *corev1.Namespace) (*Quota, error) {
+	var (
+		q    Quota
+		set  bool
+		errs []error
+	)
+	if v, ok := ns.Annotations[QuotaRecordsAnnotation]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("%s: invalid records count: %q", QuotaRecordsAnnotation, v))
+		} else {
+			q.Records, set = n, true
+		}
+	}
+	if v, ok := ns.Annotations[QuotaTypesAnnotation]; ok {
+		if types, err := parseTypesQuota(v); err != nil {
+			errs = append(errs, err)
+		} else {
+			q.Types, set = types, true
+		}
+	}
+	if v, ok := ns.Annotations[QuotaMinTTLAnnotation]; ok {
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid ttl: %q", QuotaMinTTLAnnotation, v))
+		} else {
+			q.MinTTL, set = uint32(n), true
+		}
+	}
+	if !set {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return &q, utilerrors.NewAggregate(errs)
+}
+
+func parseTypesQuota(v string) (map[string]int, error) {
+	types := make(map[string]int)
+	for _, p := range strings.Split(v, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s: invalid type quota: %q, expected TYPE=COUNT", QuotaTypesAnnotation, p)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: invalid type quota: %q, expected TYPE=COUNT", QuotaTypesAnnotation, p)
+		}
+		types[strings.ToUpper(strings.TrimSpace(parts[0]))] = n
+	}
+	return types, nil
+}
+
+// Usage returns the number of records, in total and per type
+func Usage(records []DNSRecord) (int, map[string]int) {
+	types := make(map[string]int)
+	for i := range records {
+		typ, _, _ := records[i].Spec.header()
+		types[typ]++
+	}
+	return len(records), types
+}
+
+// check returns the errors caused by adding the record, or updating the old one, to the namespace's other records
+func (q *Quota) check(r, old *DNSRecord, others []DNSRecord) (errs field.ErrorList) {
+	typ, _, ttl := r.Spec.header()
+	var oldTyp string
+	var oldTTL uint32
+	if old != nil {
+		oldTyp, _, oldTTL = old.Spec.header()
+	}
+	total, types := Usage(others)
+	// updates of existing records are not blocked by a quota lowered afterwards
+	if q.Records != 0 && old == nil && total+1 > q.Records {
+		errs = append(errs, field.Forbidden(field.NewPath("metadata").Child("namespace"), fmt.Sprintf("exceeded quota: namespace %s is limited to %d records (%s)", r.Namespace, q.Records, QuotaRecordsAnnotation)))
+	}
+	if n, ok := q.Types[typ]; ok && typ != oldTyp && types[typ]+1 > n {
+		errs = append(errs, field.Forbidden(field.NewPath("spec").Child(strings.ToLower(typ)), fmt.Sprintf("exceeded quota: namespace %s is limited to %d %s records (%s)", r.Namespace, n, typ, QuotaTypesAnnotation)))
+	}
+	if ttl < q.MinTTL && (old == nil || ttl != oldTTL) {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child(strings.ToLower(typ)).Child("ttl"), int(ttl), fmt.Sprintf("namespace %s requires a ttl of at least %d (%s)", r.Namespace, q.MinTTL, QuotaMinTTLAnnotation)))
+	}
+	return errs
+}
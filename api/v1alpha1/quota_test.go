@@ -0,0 +1,174 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestQuotaFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *Quota
+		err         string
+	}{
+		{
+			name: "no quota",
+		},
+		{
+			name:        "records",
+			annotations: map[string]string{QuotaRecordsAnnotation: " 10 "},
+			want:        &Quota{Records: 10},
+		},
+		{
+			name:        "types",
+			annotations: map[string]string{QuotaTypesAnnotation: "a=20, cname = 10,"},
+			want:        &Quota{Types: map[string]int{"A": 20, "CNAME": 10}},
+		},
+		{
+			name:        "min ttl",
+			annotations: map[string]string{QuotaMinTTLAnnotation: "300"},
+			want:        &Quota{MinTTL: 300},
+		},
+		{
+			name: "all",
+			annotations: map[string]string{
+				QuotaRecordsAnnotation: "0",
+				QuotaTypesAnnotation:   "TXT=1",
+				QuotaMinTTLAnnotation:  "60",
+			},
+			want: &Quota{Types: map[string]int{"TXT": 1}, MinTTL: 60},
+		},
+		{
+			name:        "invalid records",
+			annotations: map[string]string{QuotaRecordsAnnotation: "-1"},
+			err:         `dns.linka.cloud/quota-records: invalid records count: "-1"`,
+		},
+		{
+			name:        "invalid type quota",
+			annotations: map[string]string{QuotaTypesAnnotation: "A"},
+			err:         `dns.linka.cloud/quota-types: invalid type quota: "A", expected TYPE=COUNT`,
+		},
+		{
+			name:        "invalid type count",
+			annotations: map[string]string{QuotaTypesAnnotation: "A=many"},
+			err:         `dns.linka.cloud/quota-types: invalid type quota: "A=many", expected TYPE=COUNT`,
+		},
+		{
+			name:        "invalid ttl",
+			annotations: map[string]string{QuotaMinTTLAnnotation: "1m"},
+			err:         `dns.linka.cloud/quota-min-ttl: invalid ttl: "1m"`,
+		},
+		{
+			name: "invalid annotations are ignored",
+			annotations: map[string]string{
+				QuotaRecordsAnnotation: "10",
+				QuotaTypesAnnotation:   "A=20,CNAME",
+				QuotaMinTTLAnnotation:  "1m",
+			},
+			want: &Quota{Records: 10},
+			err:  `[dns.linka.cloud/quota-types: invalid type quota: "CNAME", expected TYPE=COUNT, dns.linka.cloud/quota-min-ttl: invalid ttl: "1m"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := QuotaFor(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, q)
+		})
+	}
+}
+
+func TestQuotaCheck(t *testing.T) {
+	a := func(name string, ttl uint32) DNSRecord {
+		return DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       DNSRecordSpec{A: &ARecord{Name: name + ".example.org.", Class: 1, Ttl: ttl, Targets: []string{"10.0.0.1"}}},
+		}
+	}
+	txt := DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "txt", Namespace: "default"},
+		Spec:       DNSRecordSpec{TXT: &TXTRecord{Name: "example.org.", Class: 1, Ttl: 300, Targets: []string{"txt"}}},
+	}
+	tests := []struct {
+		name   string
+		quota  Quota
+		r      DNSRecord
+		old    *DNSRecord
+		others []DNSRecord
+		errs   []string
+	}{
+		{
+			name:   "within quota",
+			quota:  Quota{Records: 2, Types: map[string]int{"A": 2}, MinTTL: 60},
+			r:      a("a", 300),
+			others: []DNSRecord{a("b", 300)},
+		},
+		{
+			name:   "records exceeded",
+			quota:  Quota{Records: 1},
+			r:      a("a", 300),
+			others: []DNSRecord{txt},
+			errs:   []string{"metadata.namespace: Forbidden: exceeded quota: namespace default is limited to 1 records (dns.linka.cloud/quota-records)"},
+		},
+		{
+			name:   "records exceeded on update",
+			quota:  Quota{Records: 1},
+			r:      a("a", 300),
+			old:    &txt,
+			others: []DNSRecord{a("b", 300)},
+		},
+		{
+			name:   "type exceeded",
+			quota:  Quota{Types: map[string]int{"A": 1}},
+			r:      a("a", 300),
+			others: []DNSRecord{a("b", 300), txt},
+			errs:   []string{"spec.a: Forbidden: exceeded quota: namespace default is limited to 1 A records (dns.linka.cloud/quota-types)"},
+		},
+		{
+			name:   "type exceeded by a type change",
+			quota:  Quota{Types: map[string]int{"A": 1}},
+			r:      a("a", 300),
+			old:    &txt,
+			others: []DNSRecord{a("b", 300)},
+			errs:   []string{"spec.a: Forbidden: exceeded quota: namespace default is limited to 1 A records (dns.linka.cloud/quota-types)"},
+		},
+		{
+			name:   "type kept on update",
+			quota:  Quota{Types: map[string]int{"A": 1}},
+			r:      a("a", 600),
+			old:    func() *DNSRecord { r := a("a", 300); return &r }(),
+			others: []DNSRecord{a("b", 300)},
+		},
+		{
+			name:  "ttl too low",
+			quota: Quota{MinTTL: 300},
+			r:     a("a", 60),
+			errs:  []string{"spec.a.ttl: Invalid value: 60: namespace default requires a ttl of at least 300 (dns.linka.cloud/quota-min-ttl)"},
+		},
+		{
+			name:  "ttl kept on update",
+			quota: Quota{MinTTL: 300},
+			r:     a("a", 60),
+			old:   func() *DNSRecord { r := a("a", 60); return &r }(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			for _, v := range tt.quota.check(&tt.r, tt.old, tt.others) {
+				errs = append(errs, v.Error())
+			}
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
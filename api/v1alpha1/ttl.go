@@ -0,0 +1,124 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TTLAnnotation sets the default TTL of the records generated from the annotated Ingress or Service,
+	// or of the records defined in the annotated Namespace
+	TTLAnnotation = "dns.linka.cloud/ttl"
+
+	// DefaultTTL is the records TTL when none is configured
+	DefaultTTL uint32 = 3600
+)
+
+// ParseTTL parses a TTL annotation value
+func ParseTTL(v string) (uint32, error) {
+	i, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TTL %q: %w", v, err)
+	}
+	return uint32(i), nil
+}
+
+// ResolveTTL sets the record's TTL when it is not set, using in order its namespace's TTLAnnotation,
+// its DNSZone's default TTL, then def
+func (in *DNSRecord) ResolveTTL(ctx context.Context, c client.Reader, def uint32) error {
+	ttl := in.Spec.ttl()
+	if ttl == nil || *ttl != 0 {
+		return nil
+	}
+	if in.Namespace != "" {
+		var ns corev1.Namespace
+		if err := c.Get(ctx, types.NamespacedName{Name: in.Namespace}, &ns); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if v, ok := ns.Annotations[TTLAnnotation]; ok {
+			t, err := ParseTTL(v)
+			if err != nil {
+				return fmt.Errorf("namespace %s: %w", in.Namespace, err)
+			}
+			if t != 0 {
+				*ttl = t
+				return nil
+			}
+		}
+	}
+	_, name := in.Spec.typeAndName()
+	if net.ParseIP(name) != nil {
+		name, _ = dns.ReverseAddr(name)
+	}
+	z, _, err := MatchZone(ctx, c, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if z != nil && z.Spec.DefaultTTL != 0 {
+		*ttl = z.Spec.DefaultTTL
+		return nil
+	}
+	*ttl = def
+	return nil
+}
+
+// ttl returns a pointer to the TTL of the record's type, raw records define their TTL in their data
+func (r *DNSRecordSpec) ttl() *uint32 {
+	switch {
+	case r.A != nil:
+		return &r.A.Ttl
+	case r.AAAA != nil:
+		return &r.AAAA.Ttl
+	case r.CNAME != nil:
+		return &r.CNAME.Ttl
+	case r.TXT != nil:
+		return &r.TXT.Ttl
+	case r.SRV != nil:
+		return &r.SRV.Ttl
+	case r.MX != nil:
+		return &r.MX.Ttl
+	case r.CAA != nil:
+		return &r.CAA.Ttl
+	case r.NS != nil:
+		return &r.NS.Ttl
+	case r.PTR != nil:
+		return &r.PTR.Ttl
+	case r.SVCB != nil:
+		return &r.SVCB.Ttl
+	case r.HTTPS != nil:
+		return &r.HTTPS.Ttl
+	case r.TLSA != nil:
+		return &r.TLSA.Ttl
+	case r.SPF != nil:
+		return &r.SPF.Ttl
+	case r.DMARC != nil:
+		return &r.DMARC.Ttl
+	case r.DKIM != nil:
+		return &r.DKIM.Ttl
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestResolveTTL(t *testing.T) {
+	namespace := func(ttl string) client.Object {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		if ttl != "" {
+			ns.Annotations = map[string]string{TTLAnnotation: ttl}
+		}
+		return ns
+	}
+	zone := func(ttl uint32) client.Object {
+		return &DNSZone{ObjectMeta: metav1.ObjectMeta{Name: "example"}, Spec: DNSZoneSpec{Origin: "example.org.", DefaultTTL: ttl}}
+	}
+	tests := []struct {
+		name      string
+		objs      []client.Object
+		namespace string
+		spec      DNSRecordSpec
+		want      uint32
+		err       bool
+	}{
+		{
+			name:      "record ttl",
+			objs:      []client.Object{namespace("60"), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org.", Ttl: 30}},
+			want:      30,
+		},
+		{
+			name:      "namespace ttl",
+			objs:      []client.Object{namespace("60"), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want:      60,
+		},
+		{
+			name:      "zero namespace ttl",
+			objs:      []client.Object{namespace("0"), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want:      120,
+		},
+		{
+			name:      "zone ttl",
+			objs:      []client.Object{namespace(""), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want:      120,
+		},
+		{
+			name:      "reverse zone ttl",
+			objs:      []client.Object{namespace(""), &DNSZone{ObjectMeta: metav1.ObjectMeta{Name: "reverse"}, Spec: DNSZoneSpec{Origin: "2.0.192.in-addr.arpa.", DefaultTTL: 240}}},
+			namespace: "default",
+			spec:      DNSRecordSpec{PTR: &PTRRecord{Name: "192.0.2.10", Target: "www.example.org."}},
+			want:      240,
+		},
+		{
+			name:      "default ttl",
+			objs:      []client.Object{namespace(""), zone(0)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want:      300,
+		},
+		{
+			name:      "outside of the zones",
+			objs:      []client.Object{namespace(""), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.com."}},
+			want:      300,
+		},
+		{
+			name:      "missing namespace",
+			objs:      []client.Object{zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want:      120,
+		},
+		{
+			name: "cluster record",
+			objs: []client.Object{namespace("60"), zone(120)},
+			spec: DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			want: 120,
+		},
+		{
+			name:      "invalid namespace ttl",
+			objs:      []client.Object{namespace("1h"), zone(120)},
+			namespace: "default",
+			spec:      DNSRecordSpec{A: &ARecord{Name: "www.example.org."}},
+			err:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &DNSRecord{ObjectMeta: metav1.ObjectMeta{Name: "record", Namespace: tt.namespace}, Spec: tt.spec}
+			err := rec.ResolveTTL(context.Background(), newClient(t, tt.objs...), 300)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *rec.Spec.ttl())
+		})
+	}
+}
+
+func TestResolveTTLRaw(t *testing.T) {
+	rec := &DNSRecord{ObjectMeta: metav1.ObjectMeta{Name: "record", Namespace: "default"}, Spec: DNSRecordSpec{Raw: "www.example.org. 60 IN A 192.0.2.1"}}
+	require.NoError(t, rec.ResolveTTL(context.Background(), newClient(t), 300))
+	assert.Equal(t, "www.example.org. 60 IN A 192.0.2.1", rec.Spec.Raw)
+}
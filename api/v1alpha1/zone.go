@@ -0,0 +1,75 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnszone "go.linka.cloud/k8s/dns/pkg/zone"
+)
+
+// MatchZone returns the declared DNSZone containing the name, the one with the longest origin if several do,
+// or nil if none contains it. It returns false if no DNSZone is declared.
+func MatchZone(ctx context.Context, c client.Reader, name string) (*DNSZone, bool, error) {
+	var zones DNSZoneList
+	if err := c.List(ctx, &zones); err != nil {
+		return nil, false, err
+	}
+	if len(zones.Items) == 0 {
+		return nil, false, nil
+	}
+	var origins []string
+	for _, v := range zones.Items {
+		origins = append(origins, v.Spec.Origin)
+	}
+	z, ok := dnszone.Match(name, origins)
+	if !ok {
+		return nil, true, nil
+	}
+	for i := range zones.Items {
+		if zones.Items[i].origin() == z {
+			return &zones.Items[i], true, nil
+		}
+	}
+	return nil, true, nil
+}
+
+// ZoneFor returns the fully qualified zone the name belongs to: the origin of the declared DNSZone containing it,
+// or the zone derived from the public suffix list if no DNSZone is declared.
+// It returns an empty zone if none of the declared DNSZones contains the name.
+func ZoneFor(ctx context.Context, c client.Reader, name string) (string, error) {
+	z, ok, err := MatchZone(ctx, c, name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return dnszone.For(name)
+	}
+	if z == nil {
+		return "", nil
+	}
+	return z.origin(), nil
+}
+
+// origin returns the zone's lower case, fully qualified origin
+func (in *DNSZone) origin() string {
+	return dns.Fqdn(strings.ToLower(in.Spec.Origin))
+}
@@ -0,0 +1,77 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestZoneFor(t *testing.T) {
+	zone := func(name, origin string) client.Object {
+		return &DNSZone{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: DNSZoneSpec{Origin: origin}}
+	}
+	tests := []struct {
+		name     string
+		zones    []client.Object
+		host     string
+		want     string
+		declared bool
+		err      bool
+	}{
+		{
+			name: "public suffix",
+			host: "www.example.co.uk",
+			want: "example.co.uk.",
+		},
+		{
+			name: "invalid name",
+			host: "localhost",
+			err:  true,
+		},
+		{
+			name:     "declared zone",
+			zones:    []client.Object{zone("example", "Example.org")},
+			host:     "www.example.org.",
+			want:     "example.org.",
+			declared: true,
+		},
+		{
+			name:     "longest declared zone",
+			zones:    []client.Object{zone("example", "example.org."), zone("sub", "sub.example.org.")},
+			host:     "www.sub.example.org.",
+			want:     "sub.example.org.",
+			declared: true,
+		},
+		{
+			name:     "outside of the declared zones",
+			zones:    []client.Object{zone("example", "example.org.")},
+			host:     "www.example.com.",
+			declared: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t, tt.zones...)
+			z, declared, err := MatchZone(context.Background(), c, tt.host)
+			require.NoError(t, err)
+			assert.Equal(t, tt.declared, declared)
+			if tt.declared && tt.want != "" {
+				require.NotNil(t, z)
+				assert.Equal(t, tt.want, z.origin())
+			} else {
+				assert.Nil(t, z)
+			}
+			got, err := ZoneFor(context.Background(), c, tt.host)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -22,12 +22,38 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AAAARecord) DeepCopyInto(out *AAAARecord) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AAAARecord.
+func (in *AAAARecord) DeepCopy() *AAAARecord {
+	if in == nil {
+		return nil
+	}
+	out := new(AAAARecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ARecord) DeepCopyInto(out *ARecord) {
 	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ARecord.
@@ -40,6 +66,21 @@ func (in *ARecord) DeepCopy() *ARecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAARecord) DeepCopyInto(out *CAARecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAARecord.
+func (in *CAARecord) DeepCopy() *CAARecord {
+	if in == nil {
+		return nil
+	}
+	out := new(CAARecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNAMERecord) DeepCopyInto(out *CNAMERecord) {
 	*out = *in
@@ -55,6 +96,219 @@ func (in *CNAMERecord) DeepCopy() *CNAMERecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDNSRecord) DeepCopyInto(out *ClusterDNSRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDNSRecord.
+func (in *ClusterDNSRecord) DeepCopy() *ClusterDNSRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDNSRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDNSRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDNSRecordList) DeepCopyInto(out *ClusterDNSRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterDNSRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDNSRecordList.
+func (in *ClusterDNSRecordList) DeepCopy() *ClusterDNSRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDNSRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDNSRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DKIMRecord) DeepCopyInto(out *DKIMRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DKIMRecord.
+func (in *DKIMRecord) DeepCopy() *DKIMRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DKIMRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMARCRecord) DeepCopyInto(out *DMARCRecord) {
+	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.ReportAggregate != nil {
+		in, out := &in.ReportAggregate, &out.ReportAggregate
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReportFailure != nil {
+		in, out := &in.ReportFailure, &out.ReportFailure
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMARCRecord.
+func (in *DMARCRecord) DeepCopy() *DMARCRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DMARCRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDomainPolicy) DeepCopyInto(out *DNSDomainPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDomainPolicy.
+func (in *DNSDomainPolicy) DeepCopy() *DNSDomainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDomainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSDomainPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDomainPolicyList) DeepCopyInto(out *DNSDomainPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DNSDomainPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDomainPolicyList.
+func (in *DNSDomainPolicyList) DeepCopy() *DNSDomainPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDomainPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSDomainPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDomainPolicySpec) DeepCopyInto(out *DNSDomainPolicySpec) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDomainPolicySpec.
+func (in *DNSDomainPolicySpec) DeepCopy() *DNSDomainPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDomainPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDomainPolicyStatus) DeepCopyInto(out *DNSDomainPolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDomainPolicyStatus.
+func (in *DNSDomainPolicyStatus) DeepCopy() *DNSDomainPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDomainPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSRecord) DeepCopyInto(out *DNSRecord) {
 	*out = *in
@@ -125,7 +379,12 @@ func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
 	if in.A != nil {
 		in, out := &in.A, &out.A
 		*out = new(ARecord)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AAAA != nil {
+		in, out := &in.AAAA, &out.AAAA
+		*out = new(AAAARecord)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.CNAME != nil {
 		in, out := &in.CNAME, &out.CNAME
@@ -147,6 +406,51 @@ func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
 		*out = new(MXRecord)
 		**out = **in
 	}
+	if in.CAA != nil {
+		in, out := &in.CAA, &out.CAA
+		*out = new(CAARecord)
+		**out = **in
+	}
+	if in.NS != nil {
+		in, out := &in.NS, &out.NS
+		*out = new(NSRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PTR != nil {
+		in, out := &in.PTR, &out.PTR
+		*out = new(PTRRecord)
+		**out = **in
+	}
+	if in.SVCB != nil {
+		in, out := &in.SVCB, &out.SVCB
+		*out = new(SVCBRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPS != nil {
+		in, out := &in.HTTPS, &out.HTTPS
+		*out = new(SVCBRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSA != nil {
+		in, out := &in.TLSA, &out.TLSA
+		*out = new(TLSARecord)
+		**out = **in
+	}
+	if in.SPF != nil {
+		in, out := &in.SPF, &out.SPF
+		*out = new(SPFRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DMARC != nil {
+		in, out := &in.DMARC, &out.DMARC
+		*out = new(DMARCRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DKIM != nil {
+		in, out := &in.DKIM, &out.DKIM
+		*out = new(DKIMRecord)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordSpec.
@@ -167,6 +471,22 @@ func (in *DNSRecordStatus) DeepCopyInto(out *DNSRecordStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IDs != nil {
+		in, out := &in.IDs, &out.IDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatus.
@@ -179,6 +499,101 @@ func (in *DNSRecordStatus) DeepCopy() *DNSRecordStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZone) DeepCopyInto(out *DNSZone) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZone.
+func (in *DNSZone) DeepCopy() *DNSZone {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSZone) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZoneList) DeepCopyInto(out *DNSZoneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DNSZone, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneList.
+func (in *DNSZoneList) DeepCopy() *DNSZoneList {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZoneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSZoneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZoneSpec) DeepCopyInto(out *DNSZoneSpec) {
+	*out = *in
+	out.SOA = in.SOA
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneSpec.
+func (in *DNSZoneSpec) DeepCopy() *DNSZoneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZoneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZoneStatus) DeepCopyInto(out *DNSZoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneStatus.
+func (in *DNSZoneStatus) DeepCopy() *DNSZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MXRecord) DeepCopyInto(out *MXRecord) {
 	*out = *in
@@ -194,6 +609,76 @@ func (in *MXRecord) DeepCopy() *MXRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSRecord) DeepCopyInto(out *NSRecord) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSRecord.
+func (in *NSRecord) DeepCopy() *NSRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(NSRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PTRRecord) DeepCopyInto(out *PTRRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PTRRecord.
+func (in *PTRRecord) DeepCopy() *PTRRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(PTRRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SOA) DeepCopyInto(out *SOA) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SOA.
+func (in *SOA) DeepCopy() *SOA {
+	if in == nil {
+		return nil
+	}
+	out := new(SOA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPFRecord) DeepCopyInto(out *SPFRecord) {
+	*out = *in
+	if in.Mechanisms != nil {
+		in, out := &in.Mechanisms, &out.Mechanisms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPFRecord.
+func (in *SPFRecord) DeepCopy() *SPFRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(SPFRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SRVRecord) DeepCopyInto(out *SRVRecord) {
 	*out = *in
@@ -209,6 +694,51 @@ func (in *SRVRecord) DeepCopy() *SRVRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SVCBRecord) DeepCopyInto(out *SVCBRecord) {
+	*out = *in
+	if in.Alpn != nil {
+		in, out := &in.Alpn, &out.Alpn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv4Hint != nil {
+		in, out := &in.IPv4Hint, &out.IPv4Hint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6Hint != nil {
+		in, out := &in.IPv6Hint, &out.IPv6Hint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SVCBRecord.
+func (in *SVCBRecord) DeepCopy() *SVCBRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(SVCBRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSARecord) DeepCopyInto(out *TLSARecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSARecord.
+func (in *TLSARecord) DeepCopy() *TLSARecord {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSARecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TXTRecord) DeepCopyInto(out *TXTRecord) {
 	*out = *in
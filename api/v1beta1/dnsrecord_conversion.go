@@ -0,0 +1,330 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &DNSRecord{}
+
+// ConvertTo converts the record to the v1alpha1 hub version
+func (in *DNSRecord) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.DNSRecord)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", dstRaw)
+	}
+	dst.ObjectMeta = in.ObjectMeta
+	spec, err := in.Spec.toV1alpha1()
+	if err != nil {
+		return err
+	}
+	dst.Spec = spec
+	dst.Status = v1alpha1.DNSRecordStatus{
+		Record:             in.Status.Record,
+		Active:             in.Status.Active,
+		Provider:           in.Status.Provider,
+		IDs:                in.Status.IDs,
+		DisplayName:        in.Status.DisplayName,
+		Zone:               in.Status.Zone,
+		SecretData:         in.Status.SecretData,
+		ObservedGeneration: in.Status.ObservedGeneration,
+		LastUpdateTime:     in.Status.LastUpdateTime,
+		Conditions:         in.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the record from the v1alpha1 hub version
+func (in *DNSRecord) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.DNSRecord)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", srcRaw)
+	}
+	in.ObjectMeta = src.ObjectMeta
+	in.Spec = fromV1alpha1(src.Spec)
+	ids := src.Status.IDs
+	// the deprecated id is migrated to the ids
+	if src.Status.ID != "" && !contains(ids, src.Status.ID) {
+		ids = append(ids, src.Status.ID)
+	}
+	in.Status = DNSRecordStatus{
+		Record:             src.Status.Record,
+		Active:             src.Status.Active,
+		Provider:           src.Status.Provider,
+		IDs:                ids,
+		DisplayName:        src.Status.DisplayName,
+		Zone:               src.Status.Zone,
+		SecretData:         src.Status.SecretData,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastUpdateTime:     src.Status.LastUpdateTime,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+func (s DNSRecordSpec) toV1alpha1() (v1alpha1.DNSRecordSpec, error) {
+	out := v1alpha1.DNSRecordSpec{Active: s.Active}
+	if err := s.validateRData(); err != nil {
+		return out, err
+	}
+	d := s.RData
+	switch s.Type {
+	case RecordTypeA:
+		out.A = &v1alpha1.ARecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Targets: d.A.Targets}
+	case RecordTypeAAAA:
+		out.AAAA = &v1alpha1.AAAARecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Targets: d.AAAA.Targets}
+	case RecordTypeCNAME:
+		out.CNAME = &v1alpha1.CNAMERecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Target: d.CNAME.Target}
+	case RecordTypeTXT:
+		out.TXT = &v1alpha1.TXTRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Targets: d.TXT.Values}
+	case RecordTypeSRV:
+		out.SRV = &v1alpha1.SRVRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Priority: d.SRV.Priority, Weight: d.SRV.Weight, Port: d.SRV.Port, Target: d.SRV.Target}
+	case RecordTypeMX:
+		out.MX = &v1alpha1.MXRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Preference: d.MX.Preference, Target: d.MX.Target}
+	case RecordTypeCAA:
+		out.CAA = &v1alpha1.CAARecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Flag: d.CAA.Flag, Tag: d.CAA.Tag, Value: d.CAA.Value}
+	case RecordTypeNS:
+		out.NS = &v1alpha1.NSRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Targets: d.NS.Targets}
+	case RecordTypePTR:
+		out.PTR = &v1alpha1.PTRRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Target: d.PTR.Target}
+	case RecordTypeSVCB:
+		out.SVCB = s.svcbToV1alpha1(d.SVCB)
+	case RecordTypeHTTPS:
+		out.HTTPS = s.svcbToV1alpha1(d.HTTPS)
+	case RecordTypeTLSA:
+		out.TLSA = &v1alpha1.TLSARecord{
+			Name:         s.Name,
+			Class:        s.Class,
+			Ttl:          s.TTL,
+			Usage:        d.TLSA.Usage,
+			Selector:     d.TLSA.Selector,
+			MatchingType: d.TLSA.MatchingType,
+			Certificate:  d.TLSA.Certificate,
+			SecretName:   d.TLSA.SecretName,
+		}
+	case RecordTypeSPF:
+		out.SPF = &v1alpha1.SPFRecord{Name: s.Name, Class: s.Class, Ttl: s.TTL, Mechanisms: d.SPF.Mechanisms}
+	case RecordTypeDMARC:
+		out.DMARC = &v1alpha1.DMARCRecord{
+			Name:            s.Name,
+			Class:           s.Class,
+			Ttl:             s.TTL,
+			Policy:          d.DMARC.Policy,
+			SubdomainPolicy: d.DMARC.SubdomainPolicy,
+			Percent:         d.DMARC.Percent,
+			ReportAggregate: d.DMARC.ReportAggregate,
+			ReportFailure:   d.DMARC.ReportFailure,
+			AlignmentDKIM:   d.DMARC.AlignmentDKIM,
+			AlignmentSPF:    d.DMARC.AlignmentSPF,
+			FailureOptions:  d.DMARC.FailureOptions,
+		}
+	case RecordTypeDKIM:
+		out.DKIM = &v1alpha1.DKIMRecord{
+			Name:       s.Name,
+			Class:      s.Class,
+			Ttl:        s.TTL,
+			KeyType:    d.DKIM.KeyType,
+			PublicKey:  d.DKIM.PublicKey,
+			SecretName: d.DKIM.SecretName,
+			SecretKey:  d.DKIM.SecretKey,
+			Testing:    d.DKIM.Testing,
+		}
+	case RecordTypeRaw:
+		out.Raw = d.Raw
+	default:
+		return out, fmt.Errorf("unsupported record type: %q", s.Type)
+	}
+	return out, nil
+}
+
+// validateRData returns an error if the data matching the record's type is missing or if the data of other types is set,
+// as it would be lost by the conversion
+func (s DNSRecordSpec) validateRData() error {
+	var (
+		found  bool
+		others []string
+	)
+	for _, v := range s.RData.types() {
+		if v == s.Type {
+			found = true
+			continue
+		}
+		others = append(others, "rdata."+strings.ToLower(string(v)))
+	}
+	// unknown types are reported by the conversion
+	if !found && s.Type.known() {
+		return fmt.Errorf("rdata.%s is required for %s records", strings.ToLower(string(s.Type)), s.Type)
+	}
+	if len(others) != 0 {
+		return fmt.Errorf("%s cannot be set for %s records", strings.Join(others, ", "), s.Type)
+	}
+	return nil
+}
+
+// types returns the record types of the data set
+func (d RData) types() []RecordType {
+	var out []RecordType
+	for _, v := range []struct {
+		typ RecordType
+		set bool
+	}{
+		{RecordTypeA, d.A != nil},
+		{RecordTypeAAAA, d.AAAA != nil},
+		{RecordTypeCNAME, d.CNAME != nil},
+		{RecordTypeTXT, d.TXT != nil},
+		{RecordTypeSRV, d.SRV != nil},
+		{RecordTypeMX, d.MX != nil},
+		{RecordTypeCAA, d.CAA != nil},
+		{RecordTypeNS, d.NS != nil},
+		{RecordTypePTR, d.PTR != nil},
+		{RecordTypeSVCB, d.SVCB != nil},
+		{RecordTypeHTTPS, d.HTTPS != nil},
+		{RecordTypeTLSA, d.TLSA != nil},
+		{RecordTypeSPF, d.SPF != nil},
+		{RecordTypeDMARC, d.DMARC != nil},
+		{RecordTypeDKIM, d.DKIM != nil},
+		{RecordTypeRaw, d.Raw != ""},
+	} {
+		if v.set {
+			out = append(out, v.typ)
+		}
+	}
+	return out
+}
+
+func (s DNSRecordSpec) svcbToV1alpha1(d *SVCBData) *v1alpha1.SVCBRecord {
+	return &v1alpha1.SVCBRecord{
+		Name:          s.Name,
+		Class:         s.Class,
+		Ttl:           s.TTL,
+		Priority:      d.Priority,
+		Target:        d.Target,
+		Alpn:          d.Alpn,
+		NoDefaultAlpn: d.NoDefaultAlpn,
+		Port:          d.Port,
+		IPv4Hint:      d.IPv4Hint,
+		IPv6Hint:      d.IPv6Hint,
+		ECH:           d.ECH,
+	}
+}
+
+func fromV1alpha1(s v1alpha1.DNSRecordSpec) DNSRecordSpec {
+	out := DNSRecordSpec{Active: s.Active}
+	header := func(typ RecordType, name string, class uint16, ttl uint32) {
+		out.Type, out.Name, out.Class, out.TTL = typ, name, class, ttl
+	}
+	switch {
+	case s.A != nil:
+		header(RecordTypeA, s.A.Name, s.A.Class, s.A.Ttl)
+		out.RData.A = &AddressData{Targets: s.A.AllTargets()}
+	case s.AAAA != nil:
+		header(RecordTypeAAAA, s.AAAA.Name, s.AAAA.Class, s.AAAA.Ttl)
+		out.RData.AAAA = &AddressData{Targets: s.AAAA.AllTargets()}
+	case s.CNAME != nil:
+		header(RecordTypeCNAME, s.CNAME.Name, s.CNAME.Class, s.CNAME.Ttl)
+		out.RData.CNAME = &TargetData{Target: s.CNAME.Target}
+	case s.TXT != nil:
+		header(RecordTypeTXT, s.TXT.Name, s.TXT.Class, s.TXT.Ttl)
+		out.RData.TXT = &TXTData{Values: s.TXT.Targets}
+	case s.SRV != nil:
+		header(RecordTypeSRV, s.SRV.Name, s.SRV.Class, s.SRV.Ttl)
+		out.RData.SRV = &SRVData{Priority: s.SRV.Priority, Weight: s.SRV.Weight, Port: s.SRV.Port, Target: s.SRV.Target}
+	case s.MX != nil:
+		header(RecordTypeMX, s.MX.Name, s.MX.Class, s.MX.Ttl)
+		out.RData.MX = &MXData{Preference: s.MX.Preference, Target: s.MX.Target}
+	case s.CAA != nil:
+		header(RecordTypeCAA, s.CAA.Name, s.CAA.Class, s.CAA.Ttl)
+		out.RData.CAA = &CAAData{Flag: s.CAA.Flag, Tag: s.CAA.Tag, Value: s.CAA.Value}
+	case s.NS != nil:
+		header(RecordTypeNS, s.NS.Name, s.NS.Class, s.NS.Ttl)
+		out.RData.NS = &NSData{Targets: s.NS.Targets}
+	case s.PTR != nil:
+		header(RecordTypePTR, s.PTR.Name, s.PTR.Class, s.PTR.Ttl)
+		out.RData.PTR = &TargetData{Target: s.PTR.Target}
+	case s.SVCB != nil:
+		header(RecordTypeSVCB, s.SVCB.Name, s.SVCB.Class, s.SVCB.Ttl)
+		out.RData.SVCB = svcbFromV1alpha1(s.SVCB)
+	case s.HTTPS != nil:
+		header(RecordTypeHTTPS, s.HTTPS.Name, s.HTTPS.Class, s.HTTPS.Ttl)
+		out.RData.HTTPS = svcbFromV1alpha1(s.HTTPS)
+	case s.TLSA != nil:
+		header(RecordTypeTLSA, s.TLSA.Name, s.TLSA.Class, s.TLSA.Ttl)
+		out.RData.TLSA = &TLSAData{
+			Usage:        s.TLSA.Usage,
+			Selector:     s.TLSA.Selector,
+			MatchingType: s.TLSA.MatchingType,
+			Certificate:  s.TLSA.Certificate,
+			SecretName:   s.TLSA.SecretName,
+		}
+	case s.SPF != nil:
+		header(RecordTypeSPF, s.SPF.Name, s.SPF.Class, s.SPF.Ttl)
+		out.RData.SPF = &SPFData{Mechanisms: s.SPF.Mechanisms}
+	case s.DMARC != nil:
+		header(RecordTypeDMARC, s.DMARC.Name, s.DMARC.Class, s.DMARC.Ttl)
+		out.RData.DMARC = &DMARCData{
+			Policy:          s.DMARC.Policy,
+			SubdomainPolicy: s.DMARC.SubdomainPolicy,
+			Percent:         s.DMARC.Percent,
+			ReportAggregate: s.DMARC.ReportAggregate,
+			ReportFailure:   s.DMARC.ReportFailure,
+			AlignmentDKIM:   s.DMARC.AlignmentDKIM,
+			AlignmentSPF:    s.DMARC.AlignmentSPF,
+			FailureOptions:  s.DMARC.FailureOptions,
+		}
+	case s.DKIM != nil:
+		header(RecordTypeDKIM, s.DKIM.Name, s.DKIM.Class, s.DKIM.Ttl)
+		out.RData.DKIM = &DKIMData{
+			KeyType:    s.DKIM.KeyType,
+			PublicKey:  s.DKIM.PublicKey,
+			SecretName: s.DKIM.SecretName,
+			SecretKey:  s.DKIM.SecretKey,
+			Testing:    s.DKIM.Testing,
+		}
+	case s.Raw != "":
+		out.Type = RecordTypeRaw
+		out.RData.Raw = s.Raw
+	}
+	return out
+}
+
+func svcbFromV1alpha1(r *v1alpha1.SVCBRecord) *SVCBData {
+	return &SVCBData{
+		Priority:      r.Priority,
+		Target:        r.Target,
+		Alpn:          r.Alpn,
+		NoDefaultAlpn: r.NoDefaultAlpn,
+		Port:          r.Port,
+		IPv4Hint:      r.IPv4Hint,
+		IPv6Hint:      r.IPv6Hint,
+		ECH:           r.ECH,
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, vv := range s {
+		if vv == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/ptr"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	pct := uint8(50)
+	tests := []struct {
+		name string
+		spec v1alpha1.DNSRecordSpec
+	}{
+		{name: "a", spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"192.0.2.1", "192.0.2.2"}}}},
+		{name: "aaaa", spec: v1alpha1.DNSRecordSpec{AAAA: &v1alpha1.AAAARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"2001:db8::1"}}}},
+		{name: "cname", spec: v1alpha1.DNSRecordSpec{CNAME: &v1alpha1.CNAMERecord{Name: "www.example.org.", Class: 1, Ttl: 60, Target: "example.org."}}},
+		{name: "txt", spec: v1alpha1.DNSRecordSpec{TXT: &v1alpha1.TXTRecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"a", "b"}}}},
+		{name: "srv", spec: v1alpha1.DNSRecordSpec{SRV: &v1alpha1.SRVRecord{Name: "_sip._tcp.example.org.", Class: 1, Ttl: 60, Priority: 10, Weight: 1, Port: 5060, Target: "sip.example.org."}}},
+		{name: "mx", spec: v1alpha1.DNSRecordSpec{MX: &v1alpha1.MXRecord{Name: "example.org.", Class: 1, Ttl: 60, Preference: 10, Target: "mail.example.org."}}},
+		{name: "caa", spec: v1alpha1.DNSRecordSpec{CAA: &v1alpha1.CAARecord{Name: "example.org.", Class: 1, Ttl: 60, Flag: 128, Tag: "issue", Value: "letsencrypt.org"}}},
+		{name: "ns", spec: v1alpha1.DNSRecordSpec{NS: &v1alpha1.NSRecord{Name: "sub.example.org.", Class: 1, Ttl: 60, Targets: []string{"ns1.example.org.", "ns2.example.org."}}}},
+		{name: "ptr", spec: v1alpha1.DNSRecordSpec{PTR: &v1alpha1.PTRRecord{Name: "1.2.0.192.in-addr.arpa.", Class: 1, Ttl: 60, Target: "example.org."}}},
+		{name: "svcb", spec: v1alpha1.DNSRecordSpec{SVCB: &v1alpha1.SVCBRecord{Name: "_8443._foo.example.org.", Class: 1, Ttl: 60, Priority: 1, Target: ".", Alpn: []string{"h2"}, NoDefaultAlpn: true, Port: 8443, IPv4Hint: []string{"192.0.2.1"}, IPv6Hint: []string{"2001:db8::1"}, ECH: "AAAA"}}},
+		{name: "https", spec: v1alpha1.DNSRecordSpec{HTTPS: &v1alpha1.SVCBRecord{Name: "example.org.", Class: 1, Ttl: 60, Priority: 1, Target: ".", Alpn: []string{"h2", "h3"}}}},
+		{name: "tlsa", spec: v1alpha1.DNSRecordSpec{TLSA: &v1alpha1.TLSARecord{Name: "_443._tcp.example.org.", Class: 1, Ttl: 60, Usage: 3, Selector: 1, MatchingType: 1, Certificate: "abcdef", SecretName: "tls"}}},
+		{name: "spf", spec: v1alpha1.DNSRecordSpec{SPF: &v1alpha1.SPFRecord{Name: "example.org.", Class: 1, Ttl: 60, Mechanisms: []string{"mx", "-all"}}}},
+		{name: "dmarc", spec: v1alpha1.DNSRecordSpec{DMARC: &v1alpha1.DMARCRecord{Name: "_dmarc.example.org.", Class: 1, Ttl: 60, Policy: "reject", SubdomainPolicy: "none", Percent: &pct, ReportAggregate: []string{"mailto:a@example.org"}, ReportFailure: []string{"mailto:f@example.org"}, AlignmentDKIM: "s", AlignmentSPF: "r", FailureOptions: "1"}}},
+		{name: "dkim", spec: v1alpha1.DNSRecordSpec{DKIM: &v1alpha1.DKIMRecord{Name: "mail._domainkey.example.org.", Class: 1, Ttl: 60, KeyType: "rsa", PublicKey: "key", SecretName: "dkim", SecretKey: "dkim.key", Testing: true}}},
+		{name: "raw", spec: v1alpha1.DNSRecordSpec{Raw: `example.org. 60 IN HINFO "cpu" "os"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := metav1.Now()
+			in := &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+				Spec:       tt.spec,
+				Status: v1alpha1.DNSRecordStatus{
+					Record:             "record",
+					Active:             ptr.Bool(true),
+					Provider:           "provider",
+					IDs:                []string{"1", "2"},
+					DisplayName:        "display",
+					Zone:               "example.org.",
+					SecretData:         "data",
+					ObservedGeneration: 2,
+					LastUpdateTime:     &now,
+					Conditions:         []metav1.Condition{{Type: v1alpha1.ConditionReady, Status: metav1.ConditionTrue}},
+				},
+			}
+			in.Spec.Active = ptr.Bool(false)
+			var beta DNSRecord
+			require.NoError(t, beta.ConvertFrom(in))
+			var out v1alpha1.DNSRecord
+			require.NoError(t, beta.ConvertTo(&out))
+			assert.Equal(t, in, &out)
+		})
+	}
+}
+
+func TestConversionMergesATargets(t *testing.T) {
+	in := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Target: "192.0.2.1", Targets: []string{"192.0.2.2"}}}}
+	var beta DNSRecord
+	require.NoError(t, beta.ConvertFrom(in))
+	assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, beta.Spec.RData.A.Targets)
+	var out v1alpha1.DNSRecord
+	require.NoError(t, beta.ConvertTo(&out))
+	assert.Empty(t, out.Spec.A.Target)
+	assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, out.Spec.A.Targets)
+}
+
+func TestConversionMigratesID(t *testing.T) {
+	in := &v1alpha1.DNSRecord{
+		Spec:   v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Targets: []string{"192.0.2.1"}}},
+		Status: v1alpha1.DNSRecordStatus{ID: "1"},
+	}
+	var beta DNSRecord
+	require.NoError(t, beta.ConvertFrom(in))
+	assert.Equal(t, []string{"1"}, beta.Status.IDs)
+	var out v1alpha1.DNSRecord
+	require.NoError(t, beta.ConvertTo(&out))
+	assert.Empty(t, out.Status.ID)
+	assert.Equal(t, []string{"1"}, out.Status.IDs)
+
+	// the id already in the ids is not duplicated
+	in.Status.IDs = []string{"1"}
+	require.NoError(t, beta.ConvertFrom(in))
+	assert.Equal(t, []string{"1"}, beta.Status.IDs)
+}
+
+func TestConversionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec DNSRecordSpec
+		err  string
+	}{
+		{
+			name: "missing data",
+			spec: DNSRecordSpec{Type: RecordTypeA, Name: "example.org."},
+			err:  "rdata.a is required for A records",
+		},
+		{
+			name: "other type data",
+			spec: DNSRecordSpec{Type: RecordTypeA, Name: "example.org.", RData: RData{A: &AddressData{Targets: []string{"192.0.2.1"}}, TXT: &TXTData{Values: []string{"a"}}}},
+			err:  "rdata.txt cannot be set for A records",
+		},
+		{
+			name: "only other type data",
+			spec: DNSRecordSpec{Type: RecordTypeCNAME, Name: "example.org.", RData: RData{PTR: &TargetData{Target: "example.org."}}},
+			err:  "rdata.cname is required for CNAME records",
+		},
+		{
+			name: "missing raw",
+			spec: DNSRecordSpec{Type: RecordTypeRaw},
+			err:  "rdata.raw is required for RAW records",
+		},
+		{
+			name: "unknown type",
+			spec: DNSRecordSpec{Type: "FOO"},
+			err:  `unsupported record type: "FOO"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out v1alpha1.DNSRecord
+			err := (&DNSRecord{Spec: tt.spec}).ConvertTo(&out)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -0,0 +1,301 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecordType is the record's DNS type
+// +kubebuilder:validation:Enum=A;AAAA;CNAME;TXT;SRV;MX;CAA;NS;PTR;SVCB;HTTPS;TLSA;SPF;DMARC;DKIM;RAW
+type RecordType string
+
+const (
+	RecordTypeA     RecordType = "A"
+	RecordTypeAAAA  RecordType = "AAAA"
+	RecordTypeCNAME RecordType = "CNAME"
+	RecordTypeTXT   RecordType = "TXT"
+	RecordTypeSRV   RecordType = "SRV"
+	RecordTypeMX    RecordType = "MX"
+	RecordTypeCAA   RecordType = "CAA"
+	RecordTypeNS    RecordType = "NS"
+	RecordTypePTR   RecordType = "PTR"
+	RecordTypeSVCB  RecordType = "SVCB"
+	RecordTypeHTTPS RecordType = "HTTPS"
+	RecordTypeTLSA  RecordType = "TLSA"
+	RecordTypeSPF   RecordType = "SPF"
+	RecordTypeDMARC RecordType = "DMARC"
+	RecordTypeDKIM  RecordType = "DKIM"
+	RecordTypeRaw   RecordType = "RAW"
+)
+
+// known returns true if the type is one of the supported record types
+func (t RecordType) known() bool {
+	switch t {
+	case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeTXT, RecordTypeSRV, RecordTypeMX, RecordTypeCAA, RecordTypeNS,
+		RecordTypePTR, RecordTypeSVCB, RecordTypeHTTPS, RecordTypeTLSA, RecordTypeSPF, RecordTypeDMARC, RecordTypeDKIM, RecordTypeRaw:
+		return true
+	}
+	return false
+}
+
+// DNSRecordSpec defines the desired state of DNSRecord
+type DNSRecordSpec struct {
+	// +optional
+	Active *bool `json:"active,omitempty"`
+	// Name is the record's absolute name, RAW records take it from their data
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Class uint16 `json:"class,omitempty"`
+	// +optional
+	TTL  uint32     `json:"ttl,omitempty"`
+	Type RecordType `json:"type"`
+	// RData is the record's data, only the field matching the record's type is used
+	RData RData `json:"rdata"`
+}
+
+// RData holds the typed record data
+type RData struct {
+	// +optional
+	A *AddressData `json:"a,omitempty"`
+	// +optional
+	AAAA *AddressData `json:"aaaa,omitempty"`
+	// +optional
+	CNAME *TargetData `json:"cname,omitempty"`
+	// +optional
+	TXT *TXTData `json:"txt,omitempty"`
+	// +optional
+	SRV *SRVData `json:"srv,omitempty"`
+	// +optional
+	MX *MXData `json:"mx,omitempty"`
+	// +optional
+	CAA *CAAData `json:"caa,omitempty"`
+	// +optional
+	NS *NSData `json:"ns,omitempty"`
+	// +optional
+	PTR *TargetData `json:"ptr,omitempty"`
+	// +optional
+	SVCB *SVCBData `json:"svcb,omitempty"`
+	// +optional
+	HTTPS *SVCBData `json:"https,omitempty"`
+	// +optional
+	TLSA *TLSAData `json:"tlsa,omitempty"`
+	// +optional
+	SPF *SPFData `json:"spf,omitempty"`
+	// +optional
+	DMARC *DMARCData `json:"dmarc,omitempty"`
+	// +optional
+	DKIM *DKIMData `json:"dkim,omitempty"`
+	// Raw is a record in the bind format, e.g. "example.org. 3600 IN A 192.0.2.1"
+	// +optional
+	Raw string `json:"raw,omitempty"`
+}
+
+// AddressData is used by both A and AAAA records
+type AddressData struct {
+	// Targets are the record's addresses, forming a single RRset
+	Targets []string `json:"targets"`
+}
+
+// TargetData is used by both CNAME and PTR records
+type TargetData struct {
+	Target string `json:"target"`
+}
+
+type TXTData struct {
+	Values []string `json:"values"`
+}
+
+type SRVData struct {
+	// +optional
+	Priority uint16 `json:"priority,omitempty"`
+	// +optional
+	Weight uint16 `json:"weight,omitempty"`
+	Port   uint16 `json:"port,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+type MXData struct {
+	// +optional
+	Preference uint16 `json:"preference,omitempty"`
+	Target     string `json:"target,omitempty"`
+}
+
+type CAAData struct {
+	// Flag is either 0 or 128 (issuer critical)
+	// +optional
+	Flag uint8 `json:"flag,omitempty"`
+	// Tag is one of issue, issuewild or iodef
+	// +kubebuilder:validation:Enum=issue;issuewild;iodef
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+type NSData struct {
+	Targets []string `json:"targets"`
+}
+
+// SVCBData is used by both SVCB and HTTPS records
+type SVCBData struct {
+	// Priority 0 is the alias mode, no parameters are allowed then
+	// +optional
+	Priority uint16 `json:"priority"`
+	// Target is the alternative endpoint name, "." means the record's name
+	Target string `json:"target"`
+	// Alpn are the supported protocols, e.g. h2, h3
+	// +optional
+	Alpn []string `json:"alpn,omitempty"`
+	// NoDefaultAlpn disables the protocol's default alpn (http/1.1 for HTTPS records)
+	// +optional
+	NoDefaultAlpn bool `json:"noDefaultAlpn,omitempty"`
+	// +optional
+	Port uint16 `json:"port,omitempty"`
+	// +optional
+	IPv4Hint []string `json:"ipv4hint,omitempty"`
+	// +optional
+	IPv6Hint []string `json:"ipv6hint,omitempty"`
+	// ECH is the base64 encoded ECHConfigList
+	// +optional
+	ECH string `json:"ech,omitempty"`
+}
+
+type TLSAData struct {
+	// Usage is one of 0 (PKIX-TA), 1 (PKIX-EE), 2 (DANE-TA) or 3 (DANE-EE)
+	// +kubebuilder:validation:Maximum=3
+	Usage uint8 `json:"usage"`
+	// Selector is either 0 (full certificate) or 1 (SubjectPublicKeyInfo)
+	// +kubebuilder:validation:Maximum=1
+	Selector uint8 `json:"selector"`
+	// MatchingType is one of 0 (exact match), 1 (SHA-256) or 2 (SHA-512)
+	// +kubebuilder:validation:Maximum=2
+	MatchingType uint8 `json:"matchingType"`
+	// Certificate is the hex encoded certificate association data, it is ignored when SecretName is set,
+	// the data computed by the controller being stored in the status
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+	// SecretName is the name of a kubernetes.io/tls Secret in the record's namespace
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// SPFData is rendered as a "v=spf1" TXT record
+type SPFData struct {
+	// Mechanisms are the policy's mechanisms and modifiers, e.g. mx, include:_spf.google.com, -all
+	Mechanisms []string `json:"mechanisms"`
+}
+
+// DMARCData is rendered as a "v=DMARC1" TXT record
+type DMARCData struct {
+	// +kubebuilder:validation:Enum=none;quarantine;reject
+	Policy string `json:"policy"`
+	// +kubebuilder:validation:Enum=none;quarantine;reject
+	// +optional
+	SubdomainPolicy string `json:"subdomainPolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *uint8 `json:"percent,omitempty"`
+	// +optional
+	ReportAggregate []string `json:"rua,omitempty"`
+	// +optional
+	ReportFailure []string `json:"ruf,omitempty"`
+	// +kubebuilder:validation:Enum=r;s
+	// +optional
+	AlignmentDKIM string `json:"adkim,omitempty"`
+	// +kubebuilder:validation:Enum=r;s
+	// +optional
+	AlignmentSPF string `json:"aspf,omitempty"`
+	// +optional
+	FailureOptions string `json:"fo,omitempty"`
+}
+
+// DKIMData is rendered as a "v=DKIM1" TXT record
+type DKIMData struct {
+	// +kubebuilder:validation:Enum=rsa;ed25519
+	// +optional
+	KeyType string `json:"keyType,omitempty"`
+	// PublicKey is the base64 encoded public key, it is ignored when SecretName is set,
+	// the key computed by the controller being stored in the status
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+	// +optional
+	Testing bool `json:"testing,omitempty"`
+}
+
+// DNSRecordStatus defines the observed state of DNSRecord
+type DNSRecordStatus struct {
+	Record   string `json:"record,omitempty"`
+	Active   *bool  `json:"active,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	// IDs are the provider's records ids, one per record value
+	IDs []string `json:"ids,omitempty"`
+	// DisplayName is the record's name Unicode form, only set for internationalized names
+	DisplayName string `json:"displayName,omitempty"`
+	// Zone is the DNSZone origin the record belongs to, empty when no DNSZone is declared
+	Zone string `json:"zone,omitempty"`
+	// SecretData is the record data computed from the Secret referenced by a TLSA or DKIM record:
+	// the TLSA certificate association data or the DKIM public key
+	SecretData string `json:"secretData,omitempty"`
+	// ObservedGeneration is the record's generation last synced with the provider
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastUpdateTime is the last time the record's synced state changed after a successful provider sync:
+	// its provider records, zone, conditions or observed generation. It is not updated by the syncs changing nothing
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=dnsrecords,scope=Namespaced,shortName=records;record;dns
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Active",type=boolean,JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Record",type=string,JSONPath=`.status.record`,priority=1
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Last Update",type=date,JSONPath=`.status.lastUpdateTime`,priority=1
+// +kubebuilder:printcolumn:name="Display Name",type=string,JSONPath=`.status.displayName`,priority=1
+
+// DNSRecord is the Schema for the dnsrecords API
+type DNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSRecordSpec   `json:"spec,omitempty"`
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DNSRecordList contains a list of DNSRecord
+type DNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSRecord{}, &DNSRecordList{})
+}
@@ -0,0 +1,463 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressData) DeepCopyInto(out *AddressData) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressData.
+func (in *AddressData) DeepCopy() *AddressData {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAAData) DeepCopyInto(out *CAAData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAAData.
+func (in *CAAData) DeepCopy() *CAAData {
+	if in == nil {
+		return nil
+	}
+	out := new(CAAData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DKIMData) DeepCopyInto(out *DKIMData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DKIMData.
+func (in *DKIMData) DeepCopy() *DKIMData {
+	if in == nil {
+		return nil
+	}
+	out := new(DKIMData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMARCData) DeepCopyInto(out *DMARCData) {
+	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.ReportAggregate != nil {
+		in, out := &in.ReportAggregate, &out.ReportAggregate
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReportFailure != nil {
+		in, out := &in.ReportFailure, &out.ReportFailure
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMARCData.
+func (in *DMARCData) DeepCopy() *DMARCData {
+	if in == nil {
+		return nil
+	}
+	out := new(DMARCData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecord) DeepCopyInto(out *DNSRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecord.
+func (in *DNSRecord) DeepCopy() *DNSRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordList) DeepCopyInto(out *DNSRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DNSRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordList.
+func (in *DNSRecordList) DeepCopy() *DNSRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(bool)
+		**out = **in
+	}
+	in.RData.DeepCopyInto(&out.RData)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordSpec.
+func (in *DNSRecordSpec) DeepCopy() *DNSRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordStatus) DeepCopyInto(out *DNSRecordStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IDs != nil {
+		in, out := &in.IDs, &out.IDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatus.
+func (in *DNSRecordStatus) DeepCopy() *DNSRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MXData) DeepCopyInto(out *MXData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MXData.
+func (in *MXData) DeepCopy() *MXData {
+	if in == nil {
+		return nil
+	}
+	out := new(MXData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSData) DeepCopyInto(out *NSData) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSData.
+func (in *NSData) DeepCopy() *NSData {
+	if in == nil {
+		return nil
+	}
+	out := new(NSData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RData) DeepCopyInto(out *RData) {
+	*out = *in
+	if in.A != nil {
+		in, out := &in.A, &out.A
+		*out = new(AddressData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AAAA != nil {
+		in, out := &in.AAAA, &out.AAAA
+		*out = new(AddressData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CNAME != nil {
+		in, out := &in.CNAME, &out.CNAME
+		*out = new(TargetData)
+		**out = **in
+	}
+	if in.TXT != nil {
+		in, out := &in.TXT, &out.TXT
+		*out = new(TXTData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SRV != nil {
+		in, out := &in.SRV, &out.SRV
+		*out = new(SRVData)
+		**out = **in
+	}
+	if in.MX != nil {
+		in, out := &in.MX, &out.MX
+		*out = new(MXData)
+		**out = **in
+	}
+	if in.CAA != nil {
+		in, out := &in.CAA, &out.CAA
+		*out = new(CAAData)
+		**out = **in
+	}
+	if in.NS != nil {
+		in, out := &in.NS, &out.NS
+		*out = new(NSData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PTR != nil {
+		in, out := &in.PTR, &out.PTR
+		*out = new(TargetData)
+		**out = **in
+	}
+	if in.SVCB != nil {
+		in, out := &in.SVCB, &out.SVCB
+		*out = new(SVCBData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPS != nil {
+		in, out := &in.HTTPS, &out.HTTPS
+		*out = new(SVCBData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSA != nil {
+		in, out := &in.TLSA, &out.TLSA
+		*out = new(TLSAData)
+		**out = **in
+	}
+	if in.SPF != nil {
+		in, out := &in.SPF, &out.SPF
+		*out = new(SPFData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DMARC != nil {
+		in, out := &in.DMARC, &out.DMARC
+		*out = new(DMARCData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DKIM != nil {
+		in, out := &in.DKIM, &out.DKIM
+		*out = new(DKIMData)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RData.
+func (in *RData) DeepCopy() *RData {
+	if in == nil {
+		return nil
+	}
+	out := new(RData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPFData) DeepCopyInto(out *SPFData) {
+	*out = *in
+	if in.Mechanisms != nil {
+		in, out := &in.Mechanisms, &out.Mechanisms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPFData.
+func (in *SPFData) DeepCopy() *SPFData {
+	if in == nil {
+		return nil
+	}
+	out := new(SPFData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRVData) DeepCopyInto(out *SRVData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRVData.
+func (in *SRVData) DeepCopy() *SRVData {
+	if in == nil {
+		return nil
+	}
+	out := new(SRVData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SVCBData) DeepCopyInto(out *SVCBData) {
+	*out = *in
+	if in.Alpn != nil {
+		in, out := &in.Alpn, &out.Alpn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv4Hint != nil {
+		in, out := &in.IPv4Hint, &out.IPv4Hint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6Hint != nil {
+		in, out := &in.IPv6Hint, &out.IPv6Hint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SVCBData.
+func (in *SVCBData) DeepCopy() *SVCBData {
+	if in == nil {
+		return nil
+	}
+	out := new(SVCBData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSAData) DeepCopyInto(out *TLSAData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSAData.
+func (in *TLSAData) DeepCopy() *TLSAData {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSAData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TXTData) DeepCopyInto(out *TXTData) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TXTData.
+func (in *TXTData) DeepCopy() *TXTData {
+	if in == nil {
+		return nil
+	}
+	out := new(TXTData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetData) DeepCopyInto(out *TargetData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetData.
+func (in *TargetData) DeepCopy() *TargetData {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetData)
+	in.DeepCopyInto(out)
+	return out
+}
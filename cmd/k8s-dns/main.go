@@ -30,10 +30,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	dnsv1beta1 "go.linka.cloud/k8s/dns/api/v1beta1"
 	"go.linka.cloud/k8s/dns/controllers"
 	"go.linka.cloud/k8s/dns/pkg/coredns"
 	"go.linka.cloud/k8s/dns/pkg/coredns/config"
 	"go.linka.cloud/k8s/dns/pkg/provider"
+	dnszone "go.linka.cloud/k8s/dns/pkg/zone"
 )
 
 var (
@@ -49,10 +51,17 @@ var (
 	dnsMetrics            bool
 	dnsCache              int
 	dnsAny                bool
-	externalAddress       net.IP
+	externalAddresses     []net.IP
 	dnsVerificationServer net.IP
 
-	dnsProvider string
+	dnsProvider   string
+	ptrZones      []string
+	reverseV4     int
+	reverseV6     int
+	defaultOrigin string
+	defaultTTL    uint32
+	gatewayAPI    bool
+	dnsEndpoints  bool
 
 	Root = &cobra.Command{
 		Use:   "k8s-dns",
@@ -60,6 +69,11 @@ var (
 		Run: func(cmd *cobra.Command, args []string) {
 			ctrl.SetLogger(zap.New(zap.UseDevMode(true), zap.StacktraceLevel(zap2.NewAtomicLevelAt(zapcore.FatalLevel))))
 
+			if err := dnszone.SetReversePrefixes(reverseV4, reverseV6); err != nil {
+				setupLog.Error(err, "invalid reverse zones prefix")
+				os.Exit(1)
+			}
+
 			mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 				Scheme:             scheme,
 				MetricsBindAddress: metricsAddr,
@@ -77,8 +91,8 @@ var (
 				os.Exit(1)
 			}
 
-			if externalAddress == nil {
-				externalAddress = net.ParseIP("127.0.0.1")
+			if len(externalAddresses) == 0 {
+				externalAddresses = []net.IP{net.ParseIP("127.0.0.1")}
 			}
 
 			if dnsVerificationServer == nil {
@@ -104,16 +118,22 @@ var (
 				Scheme:                mgr.GetScheme(),
 				Provider:              prov,
 				DNSVerificationServer: dnsVerificationServer.String() + ":53",
+				DefaultTTL:            defaultTTL,
 			}
 			if err = dnsReconciler.SetupWithManager(mgr); err != nil {
 				setupLog.Error(err, "unable to create controller", "controller", "DNSRecord")
 				os.Exit(1)
 			}
+			if err = (&controllers.ClusterDNSRecordReconciler{DNSRecordReconciler: dnsReconciler}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "ClusterDNSRecord")
+				os.Exit(1)
+			}
 
 			ingReconciler := &controllers.IngressReconciler{
-				Client: mgr.GetClient(),
-				Log:    ctrl.Log.WithName("controllers").WithName("Ingress"),
-				Scheme: mgr.GetScheme(),
+				Client:     mgr.GetClient(),
+				Log:        ctrl.Log.WithName("controllers").WithName("Ingress"),
+				Scheme:     mgr.GetScheme(),
+				DefaultTTL: defaultTTL,
 			}
 
 			if err := ingReconciler.SetupWithManager(mgr); err != nil {
@@ -121,10 +141,39 @@ var (
 				os.Exit(1)
 			}
 
+			if gatewayAPI {
+				gwReconciler := &controllers.GatewayReconciler{
+					Client:     mgr.GetClient(),
+					Log:        ctrl.Log.WithName("controllers").WithName("Gateway"),
+					Scheme:     mgr.GetScheme(),
+					DefaultTTL: defaultTTL,
+				}
+
+				if err := gwReconciler.SetupWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create controller", "controller", "Gateway")
+					os.Exit(1)
+				}
+			}
+
+			if dnsEndpoints {
+				epReconciler := &controllers.DNSEndpointReconciler{
+					Client:     mgr.GetClient(),
+					Log:        ctrl.Log.WithName("controllers").WithName("DNSEndpoint"),
+					Scheme:     mgr.GetScheme(),
+					DefaultTTL: defaultTTL,
+				}
+
+				if err := epReconciler.SetupWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create controller", "controller", "DNSEndpoint")
+					os.Exit(1)
+				}
+			}
+
 			svcReconciler := &controllers.ServiceReconciler{
-				Client: mgr.GetClient(),
-				Log:    ctrl.Log.WithName("controllers").WithName("Service"),
-				Scheme: mgr.GetScheme(),
+				Client:     mgr.GetClient(),
+				Log:        ctrl.Log.WithName("controllers").WithName("Service"),
+				Scheme:     mgr.GetScheme(),
+				DefaultTTL: defaultTTL,
 			}
 
 			if err := svcReconciler.SetupWithManager(mgr); err != nil {
@@ -132,24 +181,57 @@ var (
 				os.Exit(1)
 			}
 
+			revReconciler := &controllers.ReverseReconciler{
+				Client:     mgr.GetClient(),
+				Log:        ctrl.Log.WithName("controllers").WithName("Reverse"),
+				Scheme:     mgr.GetScheme(),
+				Zones:      ptrZones,
+				DefaultTTL: defaultTTL,
+			}
+
+			if err := revReconciler.SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "Reverse")
+				os.Exit(1)
+			}
+
 			if enableWebhook {
 				setupLog.Info("registering webhook")
-				if err = (&dnsv1alpha1.DNSRecord{}).SetupWebhookWithManager(mgr); err != nil {
+				if err = (&dnsv1alpha1.DNSRecord{}).SetupWebhookWithManager(mgr, dnsv1alpha1.WithDefaultOrigin(defaultOrigin), dnsv1alpha1.WithDefaultTTL(defaultTTL)); err != nil {
 					setupLog.Error(err, "unable to create webhook", "webhook", "DNSRecord")
 					os.Exit(1)
 				}
+				if err = (&dnsv1beta1.DNSRecord{}).SetupWebhookWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create webhook", "webhook", "DNSRecord", "version", "v1beta1")
+					os.Exit(1)
+				}
+				if err = (&dnsv1alpha1.ClusterDNSRecord{}).SetupWebhookWithManager(mgr, dnsv1alpha1.WithDefaultOrigin(defaultOrigin), dnsv1alpha1.WithDefaultTTL(defaultTTL)); err != nil {
+					setupLog.Error(err, "unable to create webhook", "webhook", "ClusterDNSRecord")
+					os.Exit(1)
+				}
+				if err = (&dnsv1alpha1.DNSZone{}).SetupWebhookWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create webhook", "webhook", "DNSZone")
+					os.Exit(1)
+				}
+				if err = (&dnsv1alpha1.DNSDomainPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create webhook", "webhook", "DNSDomainPolicy")
+					os.Exit(1)
+				}
 			}
 
 			if !noDNSServer {
 				dnsReconciler.DNSVerificationServer = "127.0.0.1:53"
+				var addrs []string
+				for _, v := range externalAddresses {
+					addrs = append(addrs, v.String())
+				}
 				conf, err := config.Config{
-					Forward:         dnsForward,
-					Log:             dnsLog,
-					Errors:          true,
-					Cache:           dnsCache,
-					Metrics:         dnsMetrics,
-					Any:             dnsAny,
-					ExternalAddress: externalAddress.String(),
+					Forward:           dnsForward,
+					Log:               dnsLog,
+					Errors:            true,
+					Cache:             dnsCache,
+					Metrics:           dnsMetrics,
+					Any:               dnsAny,
+					ExternalAddresses: addrs,
 				}.Render()
 				setupLog.Info("coredns config", "corefile", conf)
 				if err != nil {
@@ -172,6 +254,7 @@ var (
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = dnsv1alpha1.AddToScheme(scheme)
+	_ = dnsv1beta1.AddToScheme(scheme)
 
 	Root.Flags().StringVar(&metricsAddr, "metrics-addr", ":4299", "The address the metric endpoint binds to.")
 	Root.Flags().BoolVar(&enableLeaderElection, "enable-leader-election", false,
@@ -182,6 +265,13 @@ func init() {
 	Root.Flags().IPVar(&dnsVerificationServer, "dns-verification-server", net.ParseIP("1.1.1.1"), "DNS server to use for verification")
 
 	Root.Flags().StringVarP(&dnsProvider, "provider", "p", "coredns", "DNS provider to use")
+	Root.Flags().BoolVar(&gatewayAPI, "gateway-api", false, "Generate records from the Gateway API Gateways and their HTTPRoutes, GRPCRoutes and TLSRoutes")
+	Root.Flags().BoolVar(&dnsEndpoints, "dns-endpoints", false, "Generate records from the external-dns DNSEndpoints")
+	Root.Flags().StringSliceVar(&ptrZones, "ptr-zones", nil, "Zones for which every A and AAAA records get a PTR record")
+	Root.Flags().IntVar(&reverseV4, "reverse-ipv4-prefix", dnszone.DefaultReverseV4Prefix, "Prefix length of the in-addr.arpa. reverse zones when no DNSZone is declared")
+	Root.Flags().IntVar(&reverseV6, "reverse-ipv6-prefix", dnszone.DefaultReverseV6Prefix, "Prefix length of the ip6.arpa. reverse zones when no DNSZone is declared")
+	Root.Flags().Uint32Var(&defaultTTL, "default-ttl", dnsv1alpha1.DefaultTTL, "TTL of the records not setting one when neither their namespace nor their zone define one")
+	Root.Flags().StringVar(&defaultOrigin, "default-origin", "", "Origin in which the records relative names are expanded when their namespace does not set one")
 
 	Root.Flags().BoolVar(&noDNSServer, "no-dns", false, "Do not run in process coredns server")
 	Root.Flags().BoolVar(&dnsLog, "dns-log", false, "Enable coredns query logs")
@@ -189,7 +279,7 @@ func init() {
 	Root.Flags().BoolVar(&dnsMetrics, "dns-metrics", false, "Enable coredns metrics on 0.0.0.0:9153")
 	Root.Flags().BoolVar(&dnsAny, "dns-any", false, "Enable coredns 'any' plugin")
 	Root.Flags().IntVar(&dnsCache, "dns-cache", 0, "Enable coredns cache with ttl (in seconds)")
-	Root.Flags().IPVarP(&externalAddress, "external-address", "a", net.ParseIP("127.0.0.1"), "The external dns server address, e.g the loadbalancer service IP")
+	Root.Flags().IPSliceVarP(&externalAddresses, "external-address", "a", []net.IP{net.ParseIP("127.0.0.1")}, "The external dns server IPv4 and IPv6 addresses, e.g the loadbalancer service IPs")
 }
 
 func main() {
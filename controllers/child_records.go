@@ -6,7 +6,9 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -16,17 +18,52 @@ import (
 const (
 	HostnameAnnotation = "dns.linka.cloud/hostname"
 	TargetAnnotation   = "dns.linka.cloud/target"
-	TTLAnnotation      = "dns.linka.cloud/ttl"
+	TTLAnnotation      = dnsv1alpha1.TTLAnnotation
 	IgnoredAnnotation  = "dns.linka.cloud/ignore"
+	PTRAnnotation      = "dns.linka.cloud/ptr"
+	// HTTPSAnnotation generates an HTTPS record for the Ingress hosts, the value is the comma separated
+	// list of protocols to advertise, "true" or an empty value defaults to h2,h3
+	HTTPSAnnotation = "dns.linka.cloud/https"
+	// IPFamilyAnnotation restricts the records generated from the Ingress or Service addresses
+	// to a single IP family, either IPv4 or IPv6
+	IPFamilyAnnotation = "dns.linka.cloud/ip-family"
 
-	IngressAnnotation = "dns.linka.cloud/ingress"
-	ServiceAnnotation = "dns.linka.cloud/service"
+	IngressAnnotation     = "dns.linka.cloud/ingress"
+	ServiceAnnotation     = "dns.linka.cloud/service"
+	GatewayAnnotation     = "dns.linka.cloud/gateway"
+	DNSEndpointAnnotation = "dns.linka.cloud/dnsendpoint"
+	RecordAnnotation      = "dns.linka.cloud/record"
 
 	ownerKey = ".metadata.controller"
 )
 
-func recordName(name, typ, host string, index int) string {
-	return fmt.Sprintf("%s-%s-%s-%d", name, typ, strings.NewReplacer(".", "-", "*", "wildcard").Replace(host), index)
+func recordName(name, typ, host string) string {
+	return fmt.Sprintf("%s-%s-%s", name, typ, strings.NewReplacer(".", "-", ":", "-", "*", "wildcard").Replace(host))
+}
+
+// annotationTTL returns the TTL set by the TTLAnnotation, or zero if it is not set or invalid
+func annotationTTL(log logr.Logger, annotations map[string]string) uint32 {
+	v, ok := annotations[TTLAnnotation]
+	if !ok {
+		return 0
+	}
+	ttl, err := dnsv1alpha1.ParseTTL(v)
+	if err != nil {
+		log.Error(err, "invalid TTL annotation, using the namespace or zone default")
+	}
+	return ttl
+}
+
+// defaultRecord resolves the generated record's TTL and defaults it, so that it compares with the stored one
+func defaultRecord(ctx context.Context, c client.Reader, rec *dnsv1alpha1.DNSRecord, ttl uint32) error {
+	if ttl == 0 {
+		ttl = dnsv1alpha1.DefaultTTL
+	}
+	if err := rec.ResolveTTL(ctx, c, ttl); err != nil {
+		return err
+	}
+	rec.Default()
+	return nil
 }
 
 func childRecords(ctx context.Context, c client.Client, o client.Object, annotation string) (dnsv1alpha1.DNSRecordList, error) {
@@ -60,28 +97,29 @@ func childRecords(ctx context.Context, c client.Client, o client.Object, annotat
 func reconcileChildRecords(ctx context.Context, c client.Client, got, want dnsv1alpha1.DNSRecordList) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 	add, update, del := diffRecords(got, want)
+	// a record rejected by the webhooks does not prevent the other ones from being reconciled
+	var errs []error
 	for _, v := range del {
 		if err := c.Delete(ctx, &v); err != nil {
 			if client.IgnoreNotFound(err) != nil {
 				log.Error(err, "unable to delete DNSRecord", "name", v.Name)
-				return ctrl.Result{}, err
+				errs = append(errs, err)
 			}
 		}
 	}
 	for _, v := range add {
 		if err := c.Create(ctx, &v); err != nil {
 			log.Error(err, "unable to create DNSRecord", "name", v.Name)
-			return ctrl.Result{}, err
+			errs = append(errs, err)
 		}
 	}
 	for _, v := range update {
 		if err := c.Update(ctx, &v); err != nil {
 			log.Error(err, "unable to update DNSRecord", "name", v.Name)
-			return ctrl.Result{}, err
+			errs = append(errs, err)
 		}
 	}
-
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, utilerrors.NewAggregate(errs)
 }
 
 func diffRecords(got, want dnsv1alpha1.DNSRecordList) (add, update, del []dnsv1alpha1.DNSRecord) {
@@ -0,0 +1,79 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+// ClusterDNSRecordReconciler reconciles a ClusterDNSRecord object through the DNSRecordReconciler pipeline
+type ClusterDNSRecordReconciler struct {
+	*DNSRecordReconciler
+}
+
+// +kubebuilder:rbac:groups=dns.linka.cloud,resources=clusterdnsrecords,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dns.linka.cloud,resources=clusterdnsrecords/status,verbs=get;update;patch
+
+func (r *ClusterDNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("clusterdnsrecord", req.Name)
+	ctx = ctrl.LoggerInto(ctx, log)
+	log.V(2).Info("new request")
+	defer r.lock(req.NamespacedName.String())()
+	var rec dnsv1alpha1.ClusterDNSRecord
+	if err := r.Get(ctx, req.NamespacedName, &rec); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch record")
+		return ctrl.Result{}, err
+	}
+	return r.reconcile(ctx, req, &rec, rec.DNSRecord())
+}
+
+// SetupWithManager must be called after the DNSRecordReconciler's one, as it shares its state
+func (r *ClusterDNSRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dnsv1alpha1.ClusterDNSRecord{}).
+		Watches(&source.Kind{Type: &dnsv1alpha1.DNSZone{}}, handler.EnqueueRequestsFromMapFunc(r.allRecords)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 8}).
+		Complete(r)
+}
+
+// allRecords returns all the cluster records as their zone may have changed
+func (r *ClusterDNSRecordReconciler) allRecords(_ client.Object) []reconcile.Request {
+	var recs dnsv1alpha1.ClusterDNSRecordList
+	if err := r.List(context.Background(), &recs); err != nil {
+		r.Log.Error(err, "unable to list cluster records")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, v := range recs.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: v.Name}})
+	}
+	return reqs
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/miekg/dns"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/record"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
+)
+
+var dnsEndpointGVK = schema.GroupVersionKind{Group: "externaldns.k8s.io", Version: "v1alpha1", Kind: "DNSEndpoint"}
+
+// DNSEndpointReconciler reconciles an external-dns DNSEndpoint object, creating a DNSRecord per endpoint target.
+// The DNSEndpoints are read as unstructured objects so that the external-dns module is not required.
+type DNSEndpointReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// DefaultTTL is the records TTL when neither the endpoint, its namespace nor its zone set one
+	DefaultTTL uint32
+
+	recorder recorder.Recorder
+}
+
+// +kubebuilder:rbac:groups=externaldns.k8s.io,resources=dnsendpoints,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *DNSEndpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("dnsendpoint", req.NamespacedName)
+	ep := newUnstructured(dnsEndpointGVK)
+	if err := r.Get(ctx, req.NamespacedName, ep); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		// garbage collection should delete the DNSRecord
+		return ctrl.Result{}, nil
+	}
+	got, err := childRecords(ctx, r.Client, ep, DNSEndpointAnnotation)
+	if err != nil {
+		log.Error(err, "unable to get child DNSRecords")
+		return ctrl.Result{}, err
+	}
+	if _, ok := ep.GetAnnotations()[IgnoredAnnotation]; ok {
+		for _, v := range got.Items {
+			if err := r.Delete(ctx, &v); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					log.Error(err, "unable to delete DNSRecord", "name", v.Name)
+					return ctrl.Result{}, err
+				}
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	endpoints, _, err := unstructured.NestedSlice(ep.Object, "spec", "endpoints")
+	if err != nil {
+		log.Error(err, "invalid endpoints")
+		r.recorder.Warn(ep, "InvalidEndpoint", err.Error())
+		return ctrl.Result{}, nil
+	}
+	var want dnsv1alpha1.DNSRecordList
+	seen := make(map[string]struct{})
+	for _, v := range endpoints {
+		e, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(e, "dnsName")
+		typ, _, _ := unstructured.NestedString(e, "recordType")
+		ttl, _, _ := unstructured.NestedInt64(e, "recordTTL")
+		targets, _, _ := unstructured.NestedStringSlice(e, "targets")
+		for _, target := range targets {
+			rec, err := endpointRecord(name, strings.ToUpper(typ), uint32(ttl), target)
+			if err != nil {
+				log.Error(err, "invalid endpoint", "name", name, "type", typ, "target", target)
+				r.recorder.Warn(ep, "InvalidEndpoint", err.Error())
+				continue
+			}
+			// raw records carry their TTL in their data, so they cannot be resolved from the namespace or the zone
+			if rec.Spec.Raw != "" && ttl == 0 {
+				if rec, err = endpointRecord(name, strings.ToUpper(typ), r.defaultTTL(), target); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			rec.Name = endpointRecordName(ep.GetName(), typ, name, target)
+			if _, ok := seen[rec.Name]; ok {
+				continue
+			}
+			seen[rec.Name] = struct{}{}
+			rec.Namespace = ep.GetNamespace()
+			rec.Annotations = map[string]string{DNSEndpointAnnotation: ep.GetName()}
+			if err := defaultRecord(ctx, r.Client, &rec, r.DefaultTTL); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := ctrl.SetControllerReference(ep, &rec, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+			want.Items = append(want.Items, rec)
+		}
+	}
+	return reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+}
+
+func (r *DNSEndpointReconciler) defaultTTL() uint32 {
+	if r.DefaultTTL == 0 {
+		return dnsv1alpha1.DefaultTTL
+	}
+	return r.DefaultTTL
+}
+
+// endpointRecord parses the endpoint's target as a record of its type
+func endpointRecord(name, typ string, ttl uint32, target string) (dnsv1alpha1.DNSRecord, error) {
+	if _, ok := dns.StringToType[typ]; !ok {
+		return dnsv1alpha1.DNSRecord{}, fmt.Errorf("unsupported record type: %s", typ)
+	}
+	if strings.TrimSuffix(name, ".") == "" {
+		return dnsv1alpha1.DNSRecord{}, fmt.Errorf("invalid %s record: empty name", typ)
+	}
+	// external-dns TXT targets are unquoted values which may contain spaces
+	if typ == "TXT" && !strings.HasPrefix(target, `"`) {
+		target = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(target) + `"`
+	}
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(name), ttl, typ, target))
+	if err != nil {
+		return dnsv1alpha1.DNSRecord{}, fmt.Errorf("invalid %s record %s: %w", typ, name, err)
+	}
+	if rr == nil {
+		return dnsv1alpha1.DNSRecord{}, fmt.Errorf("invalid %s record %s: empty target", typ, name)
+	}
+	return record.FromRR(rr), nil
+}
+
+// endpointRecordName returns a name unique to the endpoint's target,
+// truncated before the target's hash so that it fits the objects names length limit
+func endpointRecordName(owner, typ, name, target string) string {
+	h := fnv.New32a()
+	h.Write([]byte(target))
+	name = strings.NewReplacer("_", "", "/", "-").Replace(strings.ToLower(strings.TrimSuffix(name, ".")))
+	suffix := fmt.Sprintf("-%x", h.Sum32())
+	base := recordName(owner, strings.ToLower(typ), name)
+	if max := validation.DNS1123SubdomainMaxLength - len(suffix); len(base) > max {
+		base = strings.TrimRight(base[:max], "-.")
+	}
+	return base + suffix
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSEndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = recorder.New(mgr.GetEventRecorderFor("DNSEndpoint"))
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("dnsendpoint").
+		For(newUnstructured(dnsEndpointGVK)).
+		Owns(&dnsv1alpha1.DNSRecord{}).
+		Complete(r)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/record"
+)
+
+// rejectingClient rejects the creation of the named records as a validating webhook would
+type rejectingClient struct {
+	client.Client
+	names map[string]struct{}
+}
+
+func (c *rejectingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := c.names[obj.GetName()]; ok {
+		return apierrors.NewForbidden(dnsv1alpha1.GroupVersion.WithResource("dnsrecords").GroupResource(), obj.GetName(), nil)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("DNSEndpointReconciler", func() {
+	It("parses the endpoints targets as records of their type", func() {
+		rec, err := endpointRecord("example.org", "TXT", 60, `v=spf1 include:"example.org" -all`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Spec.TXT).ToNot(BeNil())
+		rrs, err := record.ToRR(rec)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rrs[0].String()).To(Equal("example.org.\t60\tIN\tTXT\t" + `"v=spf1 include:\"example.org\" -all"`))
+
+		// quoted values are kept as is
+		rec, err = endpointRecord("example.org", "TXT", 60, `"a" "b"`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Spec.TXT.Targets).To(Equal([]string{"a", "b"}))
+
+		rec, err = endpointRecord("www.example.org", "CNAME", 60, "example.org")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Spec.CNAME).ToNot(BeNil())
+		Expect(rec.Spec.CNAME.Name).To(Equal("www.example.org."))
+
+		_, err = endpointRecord("example.org", "FOO", 60, "value")
+		Expect(err).To(MatchError("unsupported record type: FOO"))
+		_, err = endpointRecord("", "A", 60, "192.0.2.1")
+		Expect(err).To(MatchError("invalid A record: empty name"))
+		_, err = endpointRecord(".", "A", 60, "192.0.2.1")
+		Expect(err).To(MatchError("invalid A record: empty name"))
+		_, err = endpointRecord("example.org", "A", 60, "not-an-ip")
+		Expect(err).To(HaveOccurred())
+		_, err = endpointRecord("example.org", "A", 60, "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("names the records after the endpoints targets", func() {
+		a := endpointRecordName("endpoint", "A", "_sip._tcp.Example.org.", "192.0.2.1")
+		Expect(a).To(HavePrefix("endpoint-a-sip-tcp-example-org-"))
+		Expect(validation.IsDNS1123Subdomain(a)).To(BeEmpty())
+		Expect(endpointRecordName("endpoint", "A", "_sip._tcp.Example.org.", "192.0.2.2")).ToNot(Equal(a))
+		Expect(endpointRecordName("endpoint", "A", "_sip._tcp.example.org", "192.0.2.1")).To(Equal(a))
+
+		long := strings.Repeat("a.", 126) + "org"
+		n := endpointRecordName("endpoint", "TXT", long, "1")
+		Expect(len(n)).To(BeNumerically("<=", validation.DNS1123SubdomainMaxLength))
+		Expect(validation.IsDNS1123Subdomain(n)).To(BeEmpty())
+		Expect(endpointRecordName("endpoint", "TXT", long, "2")).ToNot(Equal(n))
+	})
+
+	It("reconciles the other records when one is rejected", func() {
+		ctx := context.Background()
+		record := func(name, ip string) dnsv1alpha1.DNSRecord {
+			return dnsv1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+				Spec:       dnsv1alpha1.DNSRecordSpec{A: &dnsv1alpha1.ARecord{Name: name + ".example.org.", Targets: []string{ip}}},
+			}
+		}
+		want := dnsv1alpha1.DNSRecordList{Items: []dnsv1alpha1.DNSRecord{record("rejected", "192.0.2.1"), record("accepted", "192.0.2.2")}}
+		c := &rejectingClient{Client: k8sClient, names: map[string]struct{}{"rejected": {}}}
+		_, err := reconcileChildRecords(ctx, c, dnsv1alpha1.DNSRecordList{}, want)
+		Expect(err).To(MatchError(ContainSubstring(`"rejected" is forbidden`)))
+		var rec dnsv1alpha1.DNSRecord
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "accepted"}, &rec)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, &rec)).To(Succeed())
+	})
+})
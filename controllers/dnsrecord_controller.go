@@ -19,18 +19,28 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/idn"
 	"go.linka.cloud/k8s/dns/pkg/provider"
 	"go.linka.cloud/k8s/dns/pkg/ptr"
 	"go.linka.cloud/k8s/dns/pkg/record"
@@ -49,31 +59,26 @@ type DNSRecordReconciler struct {
 	recorder              recorder.Recorder
 	Provider              provider.Provider
 	DNSVerificationServer string
-	mu                    sync.Mutex
-	locks                 map[string]*sync.Mutex
+	// DefaultTTL is the TTL of the records when neither their namespace nor their zone define one
+	DefaultTTL uint32
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
 }
 
 // +kubebuilder:rbac:groups=dns.linka.cloud,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dns.linka.cloud,resources=dnsrecords/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dns.linka.cloud,resources=dnszones,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dns.linka.cloud,resources=dnsdomainpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("dnsrecord", req.NamespacedName)
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("new request")
-	// prevent concurrent reconcile on the same resource
-	var (
-		mu *sync.Mutex
-		ok bool
-	)
-	r.mu.Lock()
-	if mu, ok = r.locks[req.NamespacedName.String()]; !ok {
-		mu = &sync.Mutex{}
-		r.locks[req.NamespacedName.String()] = mu
-	}
-	r.mu.Unlock()
-	mu.Lock()
-	defer mu.Unlock()
+	defer r.lock(req.NamespacedName.String())()
 	var rec dnsv1alpha1.DNSRecord
 	if err := r.Get(ctx, req.NamespacedName, &rec); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -82,43 +87,99 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		log.Error(err, "unable to fetch record")
 		return ctrl.Result{}, err
 	}
+	return r.reconcile(ctx, req, &rec, &rec)
+}
 
-	rec.Default()
-	rr, err := record.ToRR(rec)
+// lock prevents concurrent reconcile on the same resource, it returns the unlock function
+func (r *DNSRecordReconciler) lock(key string) func() {
+	r.mu.Lock()
+	mu, ok := r.locks[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		r.locks[key] = mu
+	}
+	r.mu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// reconcile runs the record pipeline on rec, the DNSRecord view of obj, which is the object stored in the cluster
+func (r *DNSRecordReconciler) reconcile(ctx context.Context, req ctrl.Request, obj client.Object, rec *dnsv1alpha1.DNSRecord) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	if err := defaultRecord(ctx, r.Client, rec, r.DefaultTTL); err != nil {
+		r.recorder.Warn(obj, "Error", err.Error())
+		log.Error(err, "resolve record TTL")
+		if rec.DeletionTimestamp.IsZero() {
+			return ctrl.Result{}, err
+		}
+		// the record is removed from the provider whatever its TTL
+		rec.Default()
+	}
+	if rec.DeletionTimestamp.IsZero() {
+		changed, err := r.resolveSecret(ctx, rec)
+		if err != nil {
+			r.recorder.Warn(obj, "Error", err.Error())
+			log.Error(err, "resolve record secret")
+			// the secret watch will trigger a new reconciliation
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		if changed {
+			log.Info("updating record data from secret")
+			if err := r.updateStatus(ctx, obj, rec); err != nil {
+				log.Error(err, "update record data")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Event(obj, "Updated", "record data updated from secret")
+			return ctrl.Result{}, nil
+		}
+	}
+	rrs, err := record.ToRR(*rec)
 	if err != nil {
-		r.recorder.Warn(&rec, "Error", err.Error())
+		r.recorder.Warn(obj, "Error", err.Error())
 		log.Error(err, "parse record")
+		if rec.DeletionTimestamp.IsZero() {
+			o := rec.DeepCopy()
+			rec.SetCondition(dnsv1alpha1.ConditionReady, metav1.ConditionFalse, dnsv1alpha1.ReasonInvalidRecord, err.Error())
+			if statusChanged(o.Status, rec.Status) {
+				if err := r.updateStatus(ctx, obj, rec); err != nil {
+					log.Error(err, "update status")
+				}
+			}
+		}
 		return ctrl.Result{}, err
 	}
 
 	if !rec.DeletionTimestamp.IsZero() {
 		log.Info("record marked for deletion: deleting")
 		o := rec.DeepCopy()
-		if r, ok, err := r.Provider.Reconcile(ctx, &rec); !ok {
+		if r, ok, err := r.Provider.Reconcile(ctx, rec); !ok {
 			return r, err
 		}
-		if rec.Status.Provider != o.Status.Provider || rec.Status.ID != o.Status.ID {
+		if statusChanged(o.Status, rec.Status) {
 			log.Info("updating record status")
-			if err := r.Status().Update(ctx, &rec); err != nil {
+			if err := r.updateStatus(ctx, obj, rec); err != nil {
 				log.Error(err, "update status")
 				return ctrl.Result{}, err
 			}
 			return ctrl.Result{}, nil
 		}
-		r.recorder.Event(&rec, "Deleted", fmt.Sprintf("Deleted DNSRecord %s", req.NamespacedName))
-		if ok := removeFinalizer(&rec); !ok {
+		r.recorder.Event(obj, "Deleted", fmt.Sprintf("Deleted %s %s", kind(obj), req.NamespacedName))
+		if ok := removeFinalizer(rec); !ok {
 			return ctrl.Result{}, nil
 		}
-		if err := r.Update(ctx, &rec); err != nil {
+		if err := r.update(ctx, obj, rec); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
 
-	if !hasFinalizer(rec) {
+	if !hasFinalizer(*rec) {
 		log.Info("setting record finalizer")
 		rec.Finalizers = append(rec.Finalizers, RecordFinalizer)
-		if err := r.Update(ctx, &rec); err != nil {
+		if err := r.update(ctx, obj, rec); err != nil {
 			log.Error(err, "set finalizer")
 			return ctrl.Result{}, err
 		}
@@ -126,59 +187,125 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	o := rec.DeepCopy()
-	if r, ok, err := r.Provider.Reconcile(ctx, &rec); !ok {
+	ok, err := r.resolvePolicies(ctx, rec)
+	if err != nil {
+		log.Error(err, "resolve domain policies")
+		return ctrl.Result{}, err
+	}
+	if !ok {
+		res, err := r.withdraw(ctx, rec)
+		if err != nil {
+			log.Error(err, "withdraw forbidden record")
+		}
+		setReady(rec)
+		r.recorder.Warn(obj, dnsv1alpha1.ReasonDomainPolicy, meta.FindStatusCondition(rec.Status.Conditions, dnsv1alpha1.ConditionForbidden).Message)
+		if statusChanged(o.Status, rec.Status) || !reflect.DeepEqual(o.Status.Active, rec.Status.Active) {
+			if err := r.updateStatus(ctx, obj, rec); err != nil {
+				log.Error(err, "update status")
+				return ctrl.Result{}, err
+			}
+		}
+		// the DNSDomainPolicies and Namespaces watches will trigger a new reconciliation
+		return res, err
+	}
+	ok, err = r.resolveZone(ctx, rec, rrs[0].Header().Name)
+	if err != nil {
+		log.Error(err, "resolve zone")
+		return ctrl.Result{}, err
+	}
+	if !ok {
+		setReady(rec)
+		r.recorder.Warn(obj, dnsv1alpha1.ReasonZoneNotFound, meta.FindStatusCondition(rec.Status.Conditions, dnsv1alpha1.ConditionZone).Message)
+		if statusChanged(o.Status, rec.Status) {
+			if err := r.updateStatus(ctx, obj, rec); err != nil {
+				log.Error(err, "update status")
+				return ctrl.Result{}, err
+			}
+		}
+		// the DNSZones watch will trigger a new reconciliation
+		return ctrl.Result{}, nil
+	}
+	if res, ok, err := r.Provider.Reconcile(ctx, rec); !ok {
 		if err != nil {
 			log.Error(err, "reconcile record")
+			// keep track of the provider's failure
+			setReady(rec)
+			if statusChanged(o.Status, rec.Status) {
+				if err := r.updateStatus(ctx, obj, rec); err != nil {
+					log.Error(err, "update status")
+				}
+			}
 		}
-		return r, err
+		return res, err
 	}
 
-	raw := rr.String()
-	if rec.Status.Provider != o.Status.Provider || rec.Status.ID != o.Status.ID || rec.Status.Record != raw {
+	raw := recordString(rrs)
+	display := displayName(rrs[0].Header().Name)
+	setReady(rec)
+	if statusChanged(o.Status, rec.Status) || rec.Status.Record != raw || rec.Status.DisplayName != display || rec.Status.ObservedGeneration != rec.Generation {
 		log.Info("updating record status")
-		rec.Status.Record = rr.String()
-		if err := r.Status().Update(ctx, &rec); err != nil {
+		rec.Status.Record = raw
+		rec.Status.DisplayName = display
+		rec.Status.ObservedGeneration = rec.Generation
+		now := metav1.Now()
+		rec.Status.LastUpdateTime = &now
+		if err := r.updateStatus(ctx, obj, rec); err != nil {
 			log.Error(err, "update status")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
 
-	if ok, err = r.lookup(ctx, rr); err != nil {
+	if ok, err = r.lookup(ctx, rrs); err != nil {
 		log.Error(err, "lookup failed")
 		return ctrl.Result{}, err
 	}
 	state := recordState(ok)
-	if ptr.ToBoolD(rec.Status.Active, false) != ok {
+	desired := ptr.ToBoolD(rec.Spec.Active, true)
+	if desired == ok {
+		rec.SetCondition(dnsv1alpha1.ConditionPropagated, metav1.ConditionTrue, dnsv1alpha1.ReasonPropagated, fmt.Sprintf("record %s", state))
+	} else {
+		rec.SetCondition(dnsv1alpha1.ConditionPropagated, metav1.ConditionFalse, dnsv1alpha1.ReasonPropagating, fmt.Sprintf("waiting for the record to be %s on %s", recordState(desired), r.DNSVerificationServer))
+	}
+	setReady(rec)
+	if ptr.ToBoolD(rec.Status.Active, false) != ok || statusChanged(o.Status, rec.Status) {
 		log.Info("updating record status", "active", ok)
-		rec.Status.Active = ptr.Bool(ok)
-		if !ok {
-			r.recorder.Warn(&rec, "Warning", fmt.Sprintf("record %s", state))
+		if ptr.ToBoolD(rec.Status.Active, false) != ok && !ok {
+			r.recorder.Warn(obj, "Warning", fmt.Sprintf("record %s", state))
 		}
-		if err := r.Status().Update(ctx, &rec); err != nil {
+		rec.Status.Active = ptr.Bool(ok)
+		if err := r.updateStatus(ctx, obj, rec); err != nil {
 			log.Error(err, "update status")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
-	if ptr.ToBoolD(rec.Spec.Active, true) != ok {
-		log.Info("status does not match desired state", "desired", *rec.Spec.Active, "actual", ok)
+	if desired != ok {
+		log.Info("status does not match desired state", "desired", desired, "actual", ok)
 		return ctrl.Result{RequeueAfter: time.Second}, nil
 	}
-	r.recorder.Event(&rec, "Success", fmt.Sprintf("record %s", state))
+	r.recorder.Event(obj, "Success", fmt.Sprintf("record %s", state))
 	return ctrl.Result{}, nil
 }
 
 func (r *DNSRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = recorder.New(mgr.GetEventRecorderFor("DNSRecord"))
 	r.locks = make(map[string]*sync.Mutex)
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &dnsv1alpha1.DNSRecord{}, secretKey, secretName); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dnsv1alpha1.DNSRecord{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretRecords)).
+		Watches(&source.Kind{Type: &dnsv1alpha1.DNSZone{}}, handler.EnqueueRequestsFromMapFunc(r.allRecords)).
+		Watches(&source.Kind{Type: &dnsv1alpha1.DNSDomainPolicy{}}, handler.EnqueueRequestsFromMapFunc(r.allRecords)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.namespaceRecords)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 8}).
 		Complete(r)
 }
 
-func (r *DNSRecordReconciler) lookup(ctx context.Context, rr dns.RR) (bool, error) {
+func (r *DNSRecordReconciler) lookup(ctx context.Context, rrs []dns.RR) (bool, error) {
+	rr := rrs[0]
 	q := &dns.Msg{
 		MsgHdr: dns.MsgHdr{
 			Id:               dns.Id(),
@@ -210,12 +337,182 @@ func (r *DNSRecordReconciler) lookup(ctx context.Context, rr dns.RR) (bool, erro
 	if err != nil {
 		return false, err
 	}
-	for _, v := range res.Answer {
-		if dns.IsDuplicate(v, rr) {
-			return true, nil
+	// delegations are answered with the NS records in the authority section
+	got := append(res.Answer, res.Ns...)
+	for _, v := range rrs {
+		found := false
+		for _, vv := range got {
+			if dns.IsDuplicate(v, vv) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
 		}
 	}
-	return false, nil
+	return true, nil
+}
+
+// update writes the record's metadata and spec through its underlying object
+func (r *DNSRecordReconciler) update(ctx context.Context, obj client.Object, rec *dnsv1alpha1.DNSRecord) error {
+	setRecord(obj, rec)
+	return r.Update(ctx, obj)
+}
+
+// updateStatus writes the record's status through its underlying object
+func (r *DNSRecordReconciler) updateStatus(ctx context.Context, obj client.Object, rec *dnsv1alpha1.DNSRecord) error {
+	setRecord(obj, rec)
+	return r.Status().Update(ctx, obj)
+}
+
+func setRecord(obj client.Object, rec *dnsv1alpha1.DNSRecord) {
+	if c, ok := obj.(*dnsv1alpha1.ClusterDNSRecord); ok {
+		c.SetDNSRecord(rec)
+	}
+}
+
+func kind(obj client.Object) string {
+	if _, ok := obj.(*dnsv1alpha1.ClusterDNSRecord); ok {
+		return "ClusterDNSRecord"
+	}
+	return "DNSRecord"
+}
+
+func recordString(rrs []dns.RR) string {
+	var parts []string
+	for _, v := range rrs {
+		parts = append(parts, v.String())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// displayName returns the name's Unicode form if it is an internationalized name
+func displayName(name string) string {
+	if !idn.IsIDN(name) {
+		return ""
+	}
+	return idn.ToUnicode(name)
+}
+
+// resolveZone sets the record's zone from the declared DNSZones, it returns false if none of them contains the record
+func (r *DNSRecordReconciler) resolveZone(ctx context.Context, rec *dnsv1alpha1.DNSRecord, name string) (bool, error) {
+	z, ok, err := dnsv1alpha1.MatchZone(ctx, r, name)
+	if err != nil {
+		return false, err
+	}
+	// without any DNSZone, the zones are derived from the public suffix list
+	if !ok {
+		rec.Status.Zone = ""
+		meta.RemoveStatusCondition(&rec.Status.Conditions, dnsv1alpha1.ConditionZone)
+		return true, nil
+	}
+	if z == nil {
+		rec.Status.Zone = ""
+		rec.SetCondition(dnsv1alpha1.ConditionZone, metav1.ConditionFalse, dnsv1alpha1.ReasonZoneNotFound, fmt.Sprintf("%s is outside of any declared DNSZone", name))
+		return false, nil
+	}
+	zone := dns.Fqdn(strings.ToLower(z.Spec.Origin))
+	rec.Status.Zone = zone
+	rec.SetCondition(dnsv1alpha1.ConditionZone, metav1.ConditionTrue, dnsv1alpha1.ReasonZoneFound, fmt.Sprintf("record belongs to zone %s", zone))
+	return true, nil
+}
+
+// resolvePolicies sets the record's Forbidden condition from the DNSDomainPolicies,
+// it returns false if the record's namespace is not allowed to define it
+func (r *DNSRecordReconciler) resolvePolicies(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (bool, error) {
+	// cluster records are not restricted by the policies
+	if rec.Namespace == "" {
+		meta.RemoveStatusCondition(&rec.Status.Conditions, dnsv1alpha1.ConditionForbidden)
+		return true, nil
+	}
+	var policies dnsv1alpha1.DNSDomainPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return false, err
+	}
+	if len(policies.Items) == 0 {
+		meta.RemoveStatusCondition(&rec.Status.Conditions, dnsv1alpha1.ConditionForbidden)
+		return true, nil
+	}
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: rec.Namespace}, &ns); err != nil {
+		return false, err
+	}
+	if err := rec.CheckDomainPolicies(policies.Items, &ns); err != nil {
+		rec.SetCondition(dnsv1alpha1.ConditionForbidden, metav1.ConditionTrue, dnsv1alpha1.ReasonDomainPolicy, err.Error())
+		return false, nil
+	}
+	meta.RemoveStatusCondition(&rec.Status.Conditions, dnsv1alpha1.ConditionForbidden)
+	return true, nil
+}
+
+// withdraw deletes the forbidden record from its provider as an inactive record would be,
+// the finalizer is kept so that the record is re-created once a policy allows it
+func (r *DNSRecordReconciler) withdraw(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (ctrl.Result, error) {
+	w := rec.DeepCopy()
+	w.Spec.Active = ptr.Bool(false)
+	res, ok, err := r.Provider.Reconcile(ctx, w)
+	if !ok {
+		return res, err
+	}
+	rec.Status.ID = w.Status.ID
+	rec.Status.IDs = w.Status.IDs
+	rec.Status.Provider = w.Status.Provider
+	rec.Status.Active = ptr.Bool(false)
+	return res, nil
+}
+
+// allRecords returns all the records as their zone or their policies may have changed
+func (r *DNSRecordReconciler) allRecords(_ client.Object) []reconcile.Request {
+	return r.records()
+}
+
+// namespaceRecords returns the namespace's records as the policies matching its labels may have changed
+func (r *DNSRecordReconciler) namespaceRecords(o client.Object) []reconcile.Request {
+	return r.records(client.InNamespace(o.GetName()))
+}
+
+func (r *DNSRecordReconciler) records(opts ...client.ListOption) []reconcile.Request {
+	var recs dnsv1alpha1.DNSRecordList
+	if err := r.List(context.Background(), &recs, opts...); err != nil {
+		r.Log.Error(err, "unable to list records")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, v := range recs.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.Namespace, Name: v.Name}})
+	}
+	return reqs
+}
+
+// setReady summarizes the record's conditions in the Ready condition
+func setReady(rec *dnsv1alpha1.DNSRecord) {
+	for _, v := range []string{dnsv1alpha1.ConditionForbidden, dnsv1alpha1.ConditionConflict} {
+		if c := meta.FindStatusCondition(rec.Status.Conditions, v); c != nil && c.Status == metav1.ConditionTrue {
+			rec.SetCondition(dnsv1alpha1.ConditionReady, metav1.ConditionFalse, c.Reason, c.Message)
+			return
+		}
+	}
+	for _, v := range []string{dnsv1alpha1.ConditionZone, dnsv1alpha1.ConditionProviderSynced, dnsv1alpha1.ConditionPropagated} {
+		c := meta.FindStatusCondition(rec.Status.Conditions, v)
+		switch {
+		// the zone condition is only set when DNSZones are declared
+		case c == nil && v == dnsv1alpha1.ConditionZone:
+		case c == nil:
+			rec.SetCondition(dnsv1alpha1.ConditionReady, metav1.ConditionFalse, dnsv1alpha1.ReasonReconciling, fmt.Sprintf("waiting for the %s condition", v))
+			return
+		case c.Status != metav1.ConditionTrue:
+			rec.SetCondition(dnsv1alpha1.ConditionReady, metav1.ConditionFalse, c.Reason, c.Message)
+			return
+		}
+	}
+	rec.SetCondition(dnsv1alpha1.ConditionReady, metav1.ConditionTrue, dnsv1alpha1.ReasonReady, "record is synced and served")
+}
+
+func statusChanged(old, new dnsv1alpha1.DNSRecordStatus) bool {
+	return old.Provider != new.Provider || old.ID != new.ID || !reflect.DeepEqual(old.IDs, new.IDs) ||
+		old.Zone != new.Zone || !reflect.DeepEqual(old.Conditions, new.Conditions) ||
+		old.ObservedGeneration != new.ObservedGeneration
 }
 
 func hasFinalizer(r dnsv1alpha1.DNSRecord) bool {
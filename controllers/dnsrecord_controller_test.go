@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	provider "go.linka.cloud/k8s/dns/pkg/provider/libdns"
+)
+
+// memoryClient is an in-memory libdns client
+type memoryClient struct {
+	mu   sync.Mutex
+	id   int
+	recs []libdns.Record
+}
+
+func (c *memoryClient) GetRecords(_ context.Context, _ string) ([]libdns.Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]libdns.Record(nil), c.recs...), nil
+}
+
+func (c *memoryClient) AppendRecords(_ context.Context, _ string, recs []libdns.Record) ([]libdns.Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []libdns.Record
+	for _, v := range recs {
+		c.id++
+		v.ID = strconv.Itoa(c.id)
+		c.recs = append(c.recs, v)
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (c *memoryClient) DeleteRecords(_ context.Context, _ string, recs []libdns.Record) ([]libdns.Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keep []libdns.Record
+	for _, v := range c.recs {
+		found := false
+		for _, vv := range recs {
+			if v.ID == vv.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keep = append(keep, v)
+		}
+	}
+	c.recs = keep
+	return recs, nil
+}
+
+// count returns the number of records named name
+func (c *memoryClient) count(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, v := range c.recs {
+		if v.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+// get returns the records named name
+func (c *memoryClient) get(name string) []libdns.Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []libdns.Record
+	for _, v := range c.recs {
+		if v.Name == name {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+var _ = Describe("DNSRecordReconciler", func() {
+	var (
+		cancel context.CancelFunc
+		mem    *memoryClient
+	)
+	// the records created by the other specs are reconciled too
+	count := func() int {
+		return mem.count("forbidden.example.org.")
+	}
+
+	BeforeEach(func() {
+		mem = &memoryClient{}
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"})
+		Expect(err).ToNot(HaveOccurred())
+		err = (&DNSRecordReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("DNSRecord"),
+			Scheme:   mgr.GetScheme(),
+			Provider: provider.New("memory", mem),
+			// the lookups are refused, the records are never reported as propagated
+			DNSVerificationServer: "127.0.0.1:1",
+		}).SetupWithManager(mgr)
+		Expect(err).ToNot(HaveOccurred())
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("withdraws the records forbidden by a DNSDomainPolicy from the provider", func() {
+		ctx := context.Background()
+
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "forbidden"}})).To(Succeed())
+		rec := &dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "forbidden", Name: "record"},
+			Spec:       dnsv1alpha1.DNSRecordSpec{A: &dnsv1alpha1.ARecord{Name: "forbidden.example.org", Targets: []string{"192.0.2.1"}}},
+		}
+		Expect(k8sClient.Create(ctx, rec)).To(Succeed())
+		Eventually(count, "10s").Should(Equal(1))
+
+		policy := &dnsv1alpha1.DNSDomainPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "forbidden"},
+			Spec:       dnsv1alpha1.DNSDomainPolicySpec{Domains: []string{"forbidden.example.org"}, Namespaces: []string{"default"}},
+		}
+		Expect(k8sClient.Create(ctx, policy)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, policy)).To(Succeed())
+		}()
+		Eventually(count, "10s").Should(Equal(0))
+		Eventually(func() bool {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(rec), rec); err != nil {
+				return false
+			}
+			return meta.IsStatusConditionTrue(rec.Status.Conditions, dnsv1alpha1.ConditionForbidden) && len(rec.Status.IDs) == 0
+		}, "10s").Should(BeTrue())
+		Expect(rec.Finalizers).To(ContainElement(RecordFinalizer))
+	})
+
+	It("publishes the records without TTL with their namespace TTL", func() {
+		ctx := context.Background()
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "namespace-ttl", Annotations: map[string]string{dnsv1alpha1.TTLAnnotation: "120"}}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		rec := &dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: "record"},
+			Spec:       dnsv1alpha1.DNSRecordSpec{A: &dnsv1alpha1.ARecord{Name: "ttl.example.org", Targets: []string{"192.0.2.1"}}},
+		}
+		Expect(k8sClient.Create(ctx, rec)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, rec)).To(Succeed())
+		}()
+		Eventually(func() []libdns.Record {
+			return mem.get("ttl.example.org.")
+		}, "10s").Should(ConsistOf(HaveField("TTL", 120*time.Second)))
+	})
+})
@@ -0,0 +1,469 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
+)
+
+const (
+	gatewayGroup = "gateway.networking.k8s.io"
+	// routeGatewayKey indexes the routes by the namespace/name of their parent Gateways
+	routeGatewayKey = ".spec.parentRefs.gateway"
+)
+
+var (
+	gatewayGVK = schema.GroupVersionKind{Group: gatewayGroup, Version: "v1", Kind: "Gateway"}
+	// routeGVKs are the routes whose hostnames are published, by the listener protocols they attach to
+	routeGVKs = map[schema.GroupVersionKind][]string{
+		{Group: gatewayGroup, Version: "v1", Kind: "HTTPRoute"}:      {"HTTP", "HTTPS"},
+		{Group: gatewayGroup, Version: "v1", Kind: "GRPCRoute"}:      {"HTTP", "HTTPS"},
+		{Group: gatewayGroup, Version: "v1alpha2", Kind: "TLSRoute"}: {"TLS"},
+	}
+)
+
+// GatewayReconciler reconciles a Gateway API Gateway object, publishing the hostnames of its attached routes
+// with the Gateway's addresses.
+// The Gateway API resources are read as unstructured objects, the routes kinds which are not installed are ignored.
+type GatewayReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// DefaultTTL is the records TTL when neither the resource, its namespace nor its zone set one
+	DefaultTTL uint32
+	// Resolver looks up the hostname targets flattened at a zone apex, net.DefaultResolver if nil
+	Resolver Resolver
+
+	recorder recorder.Recorder
+	// routes are the installed routes kinds
+	routes []schema.GroupVersionKind
+	// cache reads the routes through their routeGatewayKey index, as the client does not cache the unstructured objects
+	cache client.Reader
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways;httproutes;grpcroutes;tlsroutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("gateway", req.NamespacedName)
+	gw := newUnstructured(gatewayGVK)
+	if err := r.Get(ctx, req.NamespacedName, gw); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		// garbage collection should delete the DNSRecord
+		return ctrl.Result{}, nil
+	}
+	got, err := childRecords(ctx, r.Client, gw, GatewayAnnotation)
+	if err != nil {
+		log.Error(err, "unable to get child DNSRecords")
+		return ctrl.Result{}, err
+	}
+	annotations := gw.GetAnnotations()
+	if _, ok := annotations[IgnoredAnnotation]; ok {
+		for _, v := range got.Items {
+			if err := r.Delete(ctx, &v); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					log.Error(err, "unable to delete DNSRecord", "name", v.Name)
+					return ctrl.Result{}, err
+				}
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	ttl := annotationTTL(log, annotations)
+	t, family := annotatedTargets(log, r.recorder, gw, gatewayTargets(r.recorder, gw))
+	hosts, err := r.hostnames(ctx, gw)
+	if err != nil {
+		log.Error(err, "unable to list routes")
+		return ctrl.Result{}, err
+	}
+	var want dnsv1alpha1.DNSRecordList
+	var refresh bool
+	for _, host := range hosts {
+		if t.empty() {
+			break
+		}
+		t, flattened, err := resolveTargets(ctx, r.Client, r.Resolver, host, t)
+		if err != nil {
+			log.Error(err, "unable to resolve targets", "host", host)
+			return ctrl.Result{}, err
+		}
+		refresh = refresh || flattened
+		for _, rec := range targetRecords(gw, "gw", GatewayAnnotation, host, ttl, t.only(family)) {
+			if v, ok := annotations[PTRAnnotation]; ok && rec.Spec.CNAME == nil {
+				rec.Annotations[PTRAnnotation] = v
+			}
+			if err := defaultRecord(ctx, r.Client, &rec, r.DefaultTTL); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := ctrl.SetControllerReference(gw, &rec, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+			want.Items = append(want.Items, rec)
+		}
+	}
+	res, err := reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+	if err == nil && refresh {
+		res.RequeueAfter = flattenRefresh
+	}
+	return res, err
+}
+
+// hostnames returns the hostnames of the routes attached to the gateway's listeners and accepted by it
+func (r *GatewayReconciler) hostnames(ctx context.Context, gw *unstructured.Unstructured) ([]string, error) {
+	listeners, _, err := unstructured.NestedSlice(gw.Object, "spec", "listeners")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var hosts []string
+	for _, gvk := range r.routes {
+		routes := &unstructured.UnstructuredList{}
+		routes.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.cache.List(ctx, routes, client.MatchingFields{routeGatewayKey: gw.GetNamespace() + "/" + gw.GetName()}); err != nil {
+			return nil, err
+		}
+		for _, route := range routes.Items {
+			routeHosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			accepted := acceptedParentRefs(&route)
+			for _, ref := range parentRefs(&route) {
+				if ref.Namespace != gw.GetNamespace() || ref.Name != gw.GetName() {
+					continue
+				}
+				if _, ok := accepted[ref]; !ok {
+					continue
+				}
+				for _, v := range listeners {
+					l, ok := v.(map[string]interface{})
+					if !ok || !listenerAccepts(l, ref.SectionName, routeGVKs[gvk]) {
+						continue
+					}
+					allowed, err := r.listenerAllows(ctx, gw, l, &route, gvk)
+					if err != nil {
+						return nil, err
+					}
+					if !allowed {
+						continue
+					}
+					lh, _, _ := unstructured.NestedString(l, "hostname")
+					for _, h := range intersectHostnames(lh, routeHosts) {
+						h = strings.ToLower(h)
+						if _, ok := seen[h]; ok {
+							continue
+						}
+						seen[h] = struct{}{}
+						hosts = append(hosts, h)
+					}
+				}
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// gatewayTargets returns the Gateway's status addresses, or its hostname for the Gateways reporting only hostnames
+func gatewayTargets(rec recorder.Recorder, gw *unstructured.Unstructured) targets {
+	addrs, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	var t targets
+	var hosts []string
+	for _, v := range addrs {
+		a, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typ, _, _ := unstructured.NestedString(a, "type")
+		value, _, _ := unstructured.NestedString(a, "value")
+		switch typ {
+		case "", "IPAddress":
+			t.addIP(value)
+		case "Hostname":
+			hosts = append(hosts, value)
+		}
+	}
+	return hostnameTarget(rec, gw, t, hosts)
+}
+
+type parentRef struct {
+	Namespace   string
+	Name        string
+	SectionName string
+}
+
+// parentRefs returns the route's Gateway parent references, defaulting their namespace to the route's one
+func parentRefs(route *unstructured.Unstructured) []parentRef {
+	refs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	var out []parentRef
+	for _, v := range refs {
+		ref, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if p, ok := gatewayRef(route, ref); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// acceptedParentRefs returns the route's Gateway parent references for which its status reports
+// an Accepted condition, i.e. the parents the Gateway controllers attached the route to
+func acceptedParentRefs(route *unstructured.Unstructured) map[parentRef]struct{} {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	out := make(map[parentRef]struct{})
+	for _, v := range parents {
+		parent, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, ok, _ := unstructured.NestedMap(parent, "parentRef")
+		if !ok {
+			continue
+		}
+		p, ok := gatewayRef(route, ref)
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, v := range conditions {
+			c, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			typ, _, _ := unstructured.NestedString(c, "type")
+			status, _, _ := unstructured.NestedString(c, "status")
+			if typ == "Accepted" && status == string(metav1.ConditionTrue) {
+				out[p] = struct{}{}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// gatewayRef returns the parent reference if it references a Gateway, defaulting its namespace to the route's one
+func gatewayRef(route *unstructured.Unstructured, ref map[string]interface{}) (parentRef, bool) {
+	group, ok, _ := unstructured.NestedString(ref, "group")
+	if ok && group != gatewayGroup {
+		return parentRef{}, false
+	}
+	kind, ok, _ := unstructured.NestedString(ref, "kind")
+	if ok && kind != gatewayGVK.Kind {
+		return parentRef{}, false
+	}
+	p := parentRef{Namespace: route.GetNamespace()}
+	p.Name, _, _ = unstructured.NestedString(ref, "name")
+	p.SectionName, _, _ = unstructured.NestedString(ref, "sectionName")
+	if ns, _, _ := unstructured.NestedString(ref, "namespace"); ns != "" {
+		p.Namespace = ns
+	}
+	return p, true
+}
+
+// routeGateways returns the namespace/name of the route's parent Gateways for the routeGatewayKey index
+func routeGateways(o client.Object) []string {
+	route, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, v := range parentRefs(route) {
+		out = append(out, v.Namespace+"/"+v.Name)
+	}
+	return out
+}
+
+// listenerAccepts returns true if the listener is the referenced section, if any, and uses one of the protocols
+func listenerAccepts(l map[string]interface{}, section string, protocols []string) bool {
+	if name, _, _ := unstructured.NestedString(l, "name"); section != "" && name != section {
+		return false
+	}
+	protocol, _, _ := unstructured.NestedString(l, "protocol")
+	for _, v := range protocols {
+		if v == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerAllows returns true if the listener's allowedRoutes accept the route's kind and namespace,
+// the routes are only allowed from the Gateway's namespace by default
+func (r *GatewayReconciler) listenerAllows(ctx context.Context, gw *unstructured.Unstructured, l map[string]interface{}, route *unstructured.Unstructured, gvk schema.GroupVersionKind) (bool, error) {
+	if kinds, _, _ := unstructured.NestedSlice(l, "allowedRoutes", "kinds"); len(kinds) != 0 {
+		found := false
+		for _, v := range kinds {
+			k, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, ok, _ := unstructured.NestedString(k, "group")
+			if !ok {
+				group = gatewayGroup
+			}
+			kind, _, _ := unstructured.NestedString(k, "kind")
+			if group == gvk.Group && kind == gvk.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	from, _, _ := unstructured.NestedString(l, "allowedRoutes", "namespaces", "from")
+	switch from {
+	case "All":
+		return true, nil
+	case "Selector":
+		v, ok, _ := unstructured.NestedMap(l, "allowedRoutes", "namespaces", "selector")
+		if !ok {
+			return false, nil
+		}
+		var ls metav1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(v, &ls); err != nil {
+			return false, nil
+		}
+		sel, err := metav1.LabelSelectorAsSelector(&ls)
+		if err != nil {
+			return false, nil
+		}
+		var ns corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: route.GetNamespace()}, &ns); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return sel.Matches(labels.Set(ns.Labels)), nil
+	default:
+		return route.GetNamespace() == gw.GetNamespace(), nil
+	}
+}
+
+// intersectHostnames returns the hostnames matched by both the listener and the route,
+// as defined by the Gateway API: a listener without hostname accepts all the route's hostnames,
+// a route without hostnames gets the listener's one, and the most specific of the matching hostnames is kept
+func intersectHostnames(listener string, routes []string) []string {
+	if len(routes) == 0 {
+		if listener == "" {
+			return nil
+		}
+		return []string{listener}
+	}
+	if listener == "" {
+		return routes
+	}
+	var hosts []string
+	for _, v := range routes {
+		switch {
+		case hostnameMatches(listener, v):
+			hosts = append(hosts, v)
+		case hostnameMatches(v, listener):
+			hosts = append(hosts, listener)
+		}
+	}
+	return hosts
+}
+
+// hostnameMatches returns true if the hostname matches the pattern,
+// a wildcard pattern matching the hostnames with one or more additional labels
+func hostnameMatches(pattern, hostname string) bool {
+	pattern, hostname = strings.ToLower(pattern), strings.ToLower(hostname)
+	if pattern == hostname {
+		return true
+	}
+	return strings.HasPrefix(pattern, "*.") && strings.HasSuffix(hostname, pattern[1:])
+}
+
+func newUnstructured(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+// gateways returns the Gateways the route is attached to
+func (r *GatewayReconciler) gateways(o client.Object) []reconcile.Request {
+	route, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, v := range parentRefs(route) {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.Namespace, Name: v.Name}})
+	}
+	return reqs
+}
+
+// allGateways returns all the Gateways as the namespace's labels may change the routes their listeners allow
+func (r *GatewayReconciler) allGateways(_ client.Object) []reconcile.Request {
+	gws := &unstructured.UnstructuredList{}
+	gws.SetGroupVersionKind(gatewayGVK.GroupVersion().WithKind(gatewayGVK.Kind + "List"))
+	if err := r.List(context.Background(), gws); err != nil {
+		r.Log.Error(err, "unable to list gateways")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, v := range gws.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.GetNamespace(), Name: v.GetName()}})
+	}
+	return reqs
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = recorder.New(mgr.GetEventRecorderFor("Gateway"))
+	r.cache = mgr.GetCache()
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("gateway").
+		For(newUnstructured(gatewayGVK)).
+		Owns(&dnsv1alpha1.DNSRecord{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.allGateways))
+	for gvk := range routeGVKs {
+		if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				r.Log.Info("route kind not installed, skipping", "kind", gvk.Kind, "version", gvk.Version)
+				continue
+			}
+			return err
+		}
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), newUnstructured(gvk), routeGatewayKey, routeGateways); err != nil {
+			return err
+		}
+		r.routes = append(r.routes, gvk)
+		b = b.Watches(&source.Kind{Type: newUnstructured(gvk)}, handler.EnqueueRequestsFromMapFunc(r.gateways))
+	}
+	return b.Complete(r)
+}
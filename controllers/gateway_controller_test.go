@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+var _ = Describe("GatewayReconciler", func() {
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"})
+		Expect(err).ToNot(HaveOccurred())
+		err = (&GatewayReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("Gateway"),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr)
+		Expect(err).ToNot(HaveOccurred())
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("publishes the attached routes hostnames with the gateway addresses", func() {
+		ctx := context.Background()
+
+		createGateway(ctx, "gateway", []interface{}{
+			map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80), "hostname": "*.example.org"},
+			map[string]interface{}{"name": "tls", "protocol": "TLS", "port": int64(443)},
+		}, "192.0.2.1", "2001:db8::1")
+
+		// www.example.com is not accepted by the http listener's hostname
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "default", "http", []interface{}{"www.example.org", "www.example.com"},
+			map[string]interface{}{"name": "gateway", "sectionName": "http"}), metav1.ConditionTrue)
+		// the TLSRoute only attaches to the tls listener
+		createRoute(ctx, newRoute("TLSRoute", "v1alpha2", "default", "tls", []interface{}{"tls.example.net"},
+			map[string]interface{}{"name": "gateway"}), metav1.ConditionTrue)
+
+		Eventually(func() []string {
+			return gatewayRecords(ctx, "gateway")
+		}, "10s").Should(Equal([]string{"A tls.example.net.", "A www.example.org.", "AAAA tls.example.net.", "AAAA www.example.org."}))
+	})
+
+	It("ignores the routes the listeners do not allow", func() {
+		ctx := context.Background()
+
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "routes-allowed", Labels: map[string]string{"routes": "allowed"}}})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "routes-refused"}})).To(Succeed())
+		createGateway(ctx, "restricted", []interface{}{
+			// the routes are only allowed from the gateway's namespace by default
+			map[string]interface{}{"name": "same", "protocol": "HTTP", "port": int64(80), "hostname": "*.same.example.org"},
+			map[string]interface{}{"name": "selected", "protocol": "HTTP", "port": int64(8080), "hostname": "*.selected.example.org", "allowedRoutes": map[string]interface{}{
+				"namespaces": map[string]interface{}{"from": "Selector", "selector": map[string]interface{}{"matchLabels": map[string]interface{}{"routes": "allowed"}}},
+				"kinds":      []interface{}{map[string]interface{}{"kind": "HTTPRoute"}},
+			}},
+		}, "192.0.2.2")
+
+		parent := map[string]interface{}{"name": "restricted", "namespace": "default"}
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "routes-refused", "same", []interface{}{"www.same.example.org"}, parent), metav1.ConditionTrue)
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "routes-refused", "selected", []interface{}{"refused.selected.example.org"}, parent), metav1.ConditionTrue)
+		createRoute(ctx, newRoute("GRPCRoute", "v1", "routes-allowed", "grpc", []interface{}{"grpc.selected.example.org"}, parent), metav1.ConditionTrue)
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "routes-allowed", "selected", []interface{}{"www.selected.example.org"}, parent), metav1.ConditionTrue)
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "default", "same", []interface{}{"www.same.example.org"}, parent), metav1.ConditionTrue)
+
+		want := []string{"A www.same.example.org.", "A www.selected.example.org."}
+		Eventually(func() []string {
+			return gatewayRecords(ctx, "restricted")
+		}, "10s").Should(Equal(want))
+		Consistently(func() []string {
+			return gatewayRecords(ctx, "restricted")
+		}, "2s").Should(Equal(want))
+	})
+
+	It("ignores the routes the gateway does not accept", func() {
+		ctx := context.Background()
+
+		createGateway(ctx, "accepting", []interface{}{
+			map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80), "hostname": "*.accepting.example.org"},
+			map[string]interface{}{"name": "other", "protocol": "HTTP", "port": int64(8080), "hostname": "*.other.example.org"},
+		}, "192.0.2.3")
+
+		parent := map[string]interface{}{"name": "accepting"}
+		createRoute(ctx, newRoute("HTTPRoute", "v1", "default", "accepted", []interface{}{"www.accepting.example.org"}, parent), metav1.ConditionTrue)
+		refused := createRoute(ctx, newRoute("HTTPRoute", "v1", "default", "refused", []interface{}{"refused.accepting.example.org"}, parent), metav1.ConditionFalse)
+		// the route status is not set yet
+		Expect(k8sClient.Create(ctx, newRoute("HTTPRoute", "v1", "default", "pending", []interface{}{"pending.accepting.example.org"}, parent))).To(Succeed())
+		// the route is only accepted by another section of the gateway
+		section := newRoute("HTTPRoute", "v1", "default", "section", []interface{}{"www.other.example.org"}, map[string]interface{}{"name": "accepting", "sectionName": "other"})
+		Expect(k8sClient.Create(ctx, section)).To(Succeed())
+		setRouteStatus(ctx, section, map[string]interface{}{"name": "accepting", "sectionName": "http"}, metav1.ConditionTrue)
+
+		want := []string{"A www.accepting.example.org."}
+		Eventually(func() []string {
+			return gatewayRecords(ctx, "accepting")
+		}, "10s").Should(Equal(want))
+		Consistently(func() []string {
+			return gatewayRecords(ctx, "accepting")
+		}, "2s").Should(Equal(want))
+
+		setRouteStatus(ctx, refused, parent, metav1.ConditionTrue)
+		Eventually(func() []string {
+			return gatewayRecords(ctx, "accepting")
+		}, "10s").Should(Equal([]string{"A refused.accepting.example.org.", "A www.accepting.example.org."}))
+	})
+})
+
+// createGateway creates a Gateway in the default namespace and sets its status addresses
+func createGateway(ctx context.Context, name string, listeners []interface{}, addrs ...string) {
+	gw := newUnstructured(gatewayGVK)
+	gw.SetNamespace("default")
+	gw.SetName(name)
+	Expect(unstructured.SetNestedField(gw.Object, "example", "spec", "gatewayClassName")).To(Succeed())
+	Expect(unstructured.SetNestedSlice(gw.Object, listeners, "spec", "listeners")).To(Succeed())
+	Expect(k8sClient.Create(ctx, gw)).To(Succeed())
+	var addresses []interface{}
+	for _, v := range addrs {
+		addresses = append(addresses, map[string]interface{}{"type": "IPAddress", "value": v})
+	}
+	Expect(unstructured.SetNestedSlice(gw.Object, addresses, "status", "addresses")).To(Succeed())
+	Expect(k8sClient.Status().Update(ctx, gw)).To(Succeed())
+}
+
+func newRoute(kind, version, namespace, name string, hostnames []interface{}, parent map[string]interface{}) *unstructured.Unstructured {
+	r := newUnstructured(gatewayGVK.GroupVersion().WithKind(kind))
+	r.SetAPIVersion(gatewayGroup + "/" + version)
+	r.SetNamespace(namespace)
+	r.SetName(name)
+	Expect(unstructured.SetNestedSlice(r.Object, hostnames, "spec", "hostnames")).To(Succeed())
+	Expect(unstructured.SetNestedSlice(r.Object, []interface{}{parent}, "spec", "parentRefs")).To(Succeed())
+	return r
+}
+
+// createRoute creates the route and sets its Accepted condition for its parent
+func createRoute(ctx context.Context, route *unstructured.Unstructured, accepted metav1.ConditionStatus) *unstructured.Unstructured {
+	Expect(k8sClient.Create(ctx, route)).To(Succeed())
+	refs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	setRouteStatus(ctx, route, refs[0].(map[string]interface{}), accepted)
+	return route
+}
+
+// setRouteStatus sets the route's Accepted condition for the parent
+func setRouteStatus(ctx context.Context, route *unstructured.Unstructured, parent map[string]interface{}, accepted metav1.ConditionStatus) {
+	Expect(unstructured.SetNestedSlice(route.Object, []interface{}{map[string]interface{}{
+		"parentRef":      parent,
+		"controllerName": "example.org/gateway-controller",
+		"conditions": []interface{}{map[string]interface{}{
+			"type":               "Accepted",
+			"status":             string(accepted),
+			"reason":             "Accepted",
+			"message":            "",
+			"lastTransitionTime": "2023-01-01T00:00:00Z",
+		}},
+	}}, "status", "parents")).To(Succeed())
+	Expect(k8sClient.Status().Update(ctx, route)).To(Succeed())
+}
+
+// gatewayRecords returns the type and the name of the address records generated for the gateway
+func gatewayRecords(ctx context.Context, gateway string) []string {
+	var recs dnsv1alpha1.DNSRecordList
+	if err := k8sClient.List(ctx, &recs, client.InNamespace("default")); err != nil {
+		return nil
+	}
+	var got []string
+	for _, v := range recs.Items {
+		if v.Annotations[GatewayAnnotation] != gateway {
+			continue
+		}
+		switch {
+		case v.Spec.A != nil:
+			got = append(got, "A "+v.Spec.A.Name)
+		case v.Spec.AAAA != nil:
+			got = append(got, "AAAA "+v.Spec.AAAA.Name)
+		}
+	}
+	sort.Strings(got)
+	return got
+}
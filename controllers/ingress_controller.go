@@ -18,7 +18,7 @@ package controllers
 
 import (
 	"context"
-	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
 )
 
 // IngressReconciler reconciles an Ingress object
@@ -35,6 +36,12 @@ type IngressReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// DefaultTTL is the records TTL when neither the resource, its namespace nor its zone set one
+	DefaultTTL uint32
+	// Resolver looks up the hostname targets flattened at a zone apex, net.DefaultResolver if nil
+	Resolver Resolver
+
+	recorder recorder.Recorder
 }
 
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
@@ -77,55 +84,99 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 		return ctrl.Result{}, nil
 	}
-	var ttl uint32
-	if v, ok := ing.Annotations[TTLAnnotation]; ok {
-		i, err := strconv.ParseUint(v, 10, 32)
-		if err != nil {
-			log.Error(err, "invalid TTL annotation, using defaults: 3600")
-		} else {
-			ttl = uint32(i)
-		}
-	}
+	ttl := annotationTTL(log, ing.Annotations)
+	t, family := annotatedTargets(log, r.recorder, &ing, loadBalancerTargets(r.recorder, &ing, ing.Status.LoadBalancer))
 	var want dnsv1alpha1.DNSRecordList
-	var ips []string
-	for _, v := range ing.Status.LoadBalancer.Ingress {
-		if v.IP != "" {
-			ips = append(ips, v.IP)
-		}
-	}
+	var refresh bool
+	hosts := make(map[string]struct{})
 	for _, v := range ing.Spec.Rules {
-		if v.Host == "" {
+		if v.Host == "" || t.empty() {
 			continue
 		}
-		for i, vv := range ips {
-			rec := dnsv1alpha1.DNSRecord{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      recordName(ing.Name, "ing", v.Host, i),
-					Namespace: ing.Namespace,
-					Annotations: map[string]string{
-						IngressAnnotation: ing.Name,
-					},
-				},
-				Spec: dnsv1alpha1.DNSRecordSpec{
-					A: &dnsv1alpha1.ARecord{
-						Name:   v.Host,
-						Ttl:    ttl,
-						Target: vv,
-					},
-				},
+		if _, ok := hosts[v.Host]; ok {
+			continue
+		}
+		hosts[v.Host] = struct{}{}
+		t, flattened, err := resolveTargets(ctx, r.Client, r.Resolver, v.Host, t)
+		if err != nil {
+			log.Error(err, "unable to resolve targets", "host", v.Host)
+			return ctrl.Result{}, err
+		}
+		refresh = refresh || flattened
+		t = t.only(family)
+		for _, rec := range targetRecords(&ing, "ing", IngressAnnotation, v.Host, ttl, t) {
+			if v, ok := ing.Annotations[PTRAnnotation]; ok && rec.Spec.CNAME == nil {
+				rec.Annotations[PTRAnnotation] = v
+			}
+			if err := defaultRecord(ctx, r.Client, &rec, r.DefaultTTL); err != nil {
+				return ctrl.Result{}, err
 			}
-			rec.Default()
 			if err := ctrl.SetControllerReference(&ing, &rec, r.Scheme); err != nil {
 				return ctrl.Result{}, err
 			}
 			want.Items = append(want.Items, rec)
 		}
+		alpn, ok := httpsAlpn(ing.Annotations)
+		// the HTTPS record cannot coexist with the hostname target's CNAME
+		if !ok || t.Hostname != "" || t.empty() {
+			continue
+		}
+		https := dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      recordName(ing.Name, "https", v.Host),
+				Namespace: ing.Namespace,
+				Annotations: map[string]string{
+					IngressAnnotation: ing.Name,
+				},
+			},
+			Spec: dnsv1alpha1.DNSRecordSpec{
+				HTTPS: &dnsv1alpha1.SVCBRecord{
+					Name:     v.Host,
+					Ttl:      ttl,
+					Priority: 1,
+					Target:   ".",
+					Alpn:     alpn,
+					IPv4Hint: t.IPv4,
+					IPv6Hint: t.IPv6,
+				},
+			},
+		}
+		if err := defaultRecord(ctx, r.Client, &https, r.DefaultTTL); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := ctrl.SetControllerReference(&ing, &https, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		want.Items = append(want.Items, https)
+	}
+	res, err := reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+	if err == nil && refresh {
+		res.RequeueAfter = flattenRefresh
+	}
+	return res, err
+}
+
+// httpsAlpn returns the protocols to advertise in the Ingress HTTPS records if enabled
+func httpsAlpn(annotations map[string]string) ([]string, bool) {
+	v, ok := annotations[HTTPSAnnotation]
+	if !ok || v == "false" {
+		return nil, false
+	}
+	if v == "" || v == "true" {
+		return []string{"h2", "h3"}, true
+	}
+	var alpn []string
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			alpn = append(alpn, p)
+		}
 	}
-	return reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+	return alpn, len(alpn) != 0
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = recorder.New(mgr.GetEventRecorderFor("Ingress"))
 	fn := extractValue("networking.k8s.io/v1", "Ingress")
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &dnsv1alpha1.DNSRecord{}, ownerKey, fn); err != nil {
 		return err
@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/miekg/dns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+// ReverseReconciler creates the PTR records of the A and AAAA DNSRecords
+type ReverseReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// Zones are the zones for which every A and AAAA records get a PTR record
+	Zones []string
+	// DefaultTTL is the records TTL when neither the record, its namespace nor its zone set one
+	DefaultTTL uint32
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *ReverseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("dnsrecord", req.NamespacedName)
+	var rec dnsv1alpha1.DNSRecord
+	if err := r.Get(ctx, req.NamespacedName, &rec); err != nil {
+		// garbage collection should delete the PTR records
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// the PTR records use the TTL the source record is served with
+	if err := defaultRecord(ctx, r.Client, &rec, r.DefaultTTL); err != nil {
+		log.Error(err, "unable to resolve the record TTL")
+		return ctrl.Result{}, err
+	}
+	got, err := childRecords(ctx, r.Client, &rec, RecordAnnotation)
+	if err != nil {
+		log.Error(err, "unable to get child DNSRecords")
+		return ctrl.Result{}, err
+	}
+	var want dnsv1alpha1.DNSRecordList
+	// the records which are no longer A or AAAA records keep no PTR records
+	if rec.DeletionTimestamp.IsZero() && r.enabled(log, &rec) {
+		name, ttl, targets := addresses(&rec)
+		for _, v := range targets {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				log.Error(fmt.Errorf("invalid address: %s", v), "invalid address", "address", v)
+				continue
+			}
+			reverse, err := dns.ReverseAddr(ip.String())
+			if err != nil {
+				log.Error(err, "invalid address", "address", v)
+				continue
+			}
+			ptr := dnsv1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      recordName(rec.Name, "ptr", addressName(ip)),
+					Namespace: rec.Namespace,
+					Annotations: map[string]string{
+						RecordAnnotation: rec.Name,
+					},
+				},
+				Spec: dnsv1alpha1.DNSRecordSpec{
+					Active: rec.Spec.Active,
+					PTR: &dnsv1alpha1.PTRRecord{
+						Name:   reverse,
+						Ttl:    ttl,
+						Target: name,
+					},
+				},
+			}
+			ptr.Default()
+			if err := ctrl.SetControllerReference(&rec, &ptr, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+			want.Items = append(want.Items, ptr)
+		}
+	}
+	return reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+}
+
+// enabled returns true if the record is an A or AAAA record and if it has the ptr annotation
+// or belongs to one of the configured zones
+func (r *ReverseReconciler) enabled(log logr.Logger, rec *dnsv1alpha1.DNSRecord) bool {
+	if rec.Spec.A == nil && rec.Spec.AAAA == nil {
+		return false
+	}
+	if v, ok := rec.Annotations[PTRAnnotation]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Error(err, "invalid ptr annotation", "value", v)
+		}
+		return b
+	}
+	name, _, _ := addresses(rec)
+	for _, v := range r.Zones {
+		if dns.IsSubDomain(v, dns.Fqdn(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressName returns the address in a form usable in an object name: the ipv4 dotted form
+// or the ipv6 full hexadecimal form, as the ipv6 text form may start or end with "::"
+func addressName(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.String()
+	}
+	return hex.EncodeToString(ip.To16())
+}
+
+func addresses(rec *dnsv1alpha1.DNSRecord) (name string, ttl uint32, targets []string) {
+	if rec.Spec.A != nil {
+		return rec.Spec.A.Name, rec.Spec.A.Ttl, rec.Spec.A.AllTargets()
+	}
+	return rec.Spec.AAAA.Name, rec.Spec.AAAA.Ttl, rec.Spec.AAAA.AllTargets()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReverseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	for i, v := range r.Zones {
+		r.Zones[i] = dns.Fqdn(v)
+	}
+	filter := func(o client.Object) bool {
+		rec, ok := o.(*dnsv1alpha1.DNSRecord)
+		if !ok {
+			return false
+		}
+		return r.enabled(r.Log, rec)
+	}
+	// the owned PTR records are not filtered: the predicate only applies to the source records
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return filter(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return filter(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// the records which are no longer enabled still need their PTR records to be deleted
+			if filter(e.ObjectOld) {
+				return true
+			}
+			return filter(e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return filter(e.Object)
+		},
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("reverse").
+		For(&dnsv1alpha1.DNSRecord{}, builder.WithPredicates(p)).
+		Owns(&dnsv1alpha1.DNSRecord{}).
+		Complete(r)
+}
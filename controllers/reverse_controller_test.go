@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+var _ = Describe("ReverseReconciler", func() {
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"})
+		Expect(err).ToNot(HaveOccurred())
+		err = (&ReverseReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("Reverse"),
+			Scheme: mgr.GetScheme(),
+			Zones:  []string{"reverse.example.org"},
+		}).SetupWithManager(mgr)
+		Expect(err).ToNot(HaveOccurred())
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("creates the PTR records with the source record TTL and deletes the stale ones", func() {
+		ctx := context.Background()
+
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "reverse",
+			Annotations: map[string]string{TTLAnnotation: "120"},
+		}})).To(Succeed())
+		rec := &dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "reverse", Name: "www"},
+			Spec:       dnsv1alpha1.DNSRecordSpec{A: &dnsv1alpha1.ARecord{Name: "www.reverse.example.org", Targets: []string{"192.0.2.10", "192.0.2.11"}}},
+		}
+		Expect(k8sClient.Create(ctx, rec)).To(Succeed())
+		Eventually(func() []string {
+			return ptrRecords(ctx, "reverse", "www")
+		}, "10s").Should(Equal([]string{
+			"10.2.0.192.in-addr.arpa. 120 www.reverse.example.org.",
+			"11.2.0.192.in-addr.arpa. 120 www.reverse.example.org.",
+		}))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rec), rec)).To(Succeed())
+		rec.Spec.A.Targets = []string{"192.0.2.11"}
+		Expect(k8sClient.Update(ctx, rec)).To(Succeed())
+		Eventually(func() []string {
+			return ptrRecords(ctx, "reverse", "www")
+		}, "10s").Should(Equal([]string{"11.2.0.192.in-addr.arpa. 120 www.reverse.example.org."}))
+	})
+
+	It("deletes the PTR records when the ptr annotation disables them", func() {
+		ctx := context.Background()
+
+		rec := &dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "disabled"},
+			Spec:       dnsv1alpha1.DNSRecordSpec{AAAA: &dnsv1alpha1.AAAARecord{Name: "disabled.reverse.example.org", Ttl: 60, Targets: []string{"2001:db8::1"}}},
+		}
+		Expect(k8sClient.Create(ctx, rec)).To(Succeed())
+		Eventually(func() []string {
+			return ptrRecords(ctx, "default", "disabled")
+		}, "10s").Should(Equal([]string{"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa. 60 disabled.reverse.example.org."}))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rec), rec)).To(Succeed())
+		rec.Annotations = map[string]string{PTRAnnotation: "false"}
+		Expect(k8sClient.Update(ctx, rec)).To(Succeed())
+		Eventually(func() []string {
+			return ptrRecords(ctx, "default", "disabled")
+		}, "10s").Should(BeEmpty())
+	})
+})
+
+// ptrRecords returns the name, the TTL and the target of the PTR records generated for the record
+func ptrRecords(ctx context.Context, namespace, record string) []string {
+	var recs dnsv1alpha1.DNSRecordList
+	if err := k8sClient.List(ctx, &recs, client.InNamespace(namespace)); err != nil {
+		return nil
+	}
+	var got []string
+	for _, v := range recs.Items {
+		if v.Annotations[RecordAnnotation] != record || v.Spec.PTR == nil {
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s %d %s", v.Spec.PTR.Name, v.Spec.PTR.Ttl, v.Spec.PTR.Target))
+	}
+	sort.Strings(got)
+	return got
+}
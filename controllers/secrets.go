@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+const secretKey = ".spec.secretName"
+
+// resolveSecret updates the record's status data computed from its Secret, it returns true if the status changed.
+// The data is not written to the spec so that it does not drift from the applied manifests.
+func (r *DNSRecordReconciler) resolveSecret(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (bool, error) {
+	var (
+		data string
+		err  error
+	)
+	switch {
+	case rec.Spec.TLSA != nil && rec.Spec.TLSA.SecretName != "":
+		data, err = r.tlsaCertificate(ctx, rec)
+	case rec.Spec.DKIM != nil && rec.Spec.DKIM.SecretName != "":
+		data, err = r.dkimPublicKey(ctx, rec)
+	}
+	if err != nil || data == rec.Status.SecretData {
+		return false, err
+	}
+	rec.Status.SecretData = data
+	return true, nil
+}
+
+// tlsaCertificate computes the TLSA record's certificate association data from its Secret
+func (r *DNSRecordReconciler) tlsaCertificate(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (string, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rec.Namespace, Name: rec.Spec.TLSA.SecretName}, &secret); err != nil {
+		return "", err
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		return "", fmt.Errorf("secret %s is not a %s secret", secret.Name, corev1.SecretTypeTLS)
+	}
+	certs, err := parseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", secret.Name, err)
+	}
+	cert := certs[0]
+	// trust anchor usages: PKIX-TA and DANE-TA
+	if rec.Spec.TLSA.Usage == 0 || rec.Spec.TLSA.Usage == 2 {
+		cert = certs[len(certs)-1]
+		if ca, ok := secret.Data["ca.crt"]; ok && len(ca) != 0 {
+			cas, err := parseCertificates(ca)
+			if err != nil {
+				return "", fmt.Errorf("secret %s: ca.crt: %w", secret.Name, err)
+			}
+			cert = cas[0]
+		}
+	}
+	return dns.CertificateToDANE(rec.Spec.TLSA.Selector, rec.Spec.TLSA.MatchingType, cert)
+}
+
+// dkimPublicKey returns the DKIM record's public key from the PEM encoded private or public key stored in its Secret
+func (r *DNSRecordReconciler) dkimPublicKey(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (string, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rec.Namespace, Name: rec.Spec.DKIM.SecretName}, &secret); err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(secret.Data[rec.Spec.DKIM.SecretKey])
+	if block == nil {
+		return "", fmt.Errorf("secret %s: no PEM encoded key found in %s", secret.Name, rec.Spec.DKIM.SecretKey)
+	}
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", secret.Name, err)
+	}
+	if s, ok := key.(crypto.Signer); ok {
+		key = s.Public()
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if rec.Spec.DKIM.KeyType != "rsa" {
+			return "", fmt.Errorf("secret %s: expected a %s key, got a rsa key", secret.Name, rec.Spec.DKIM.KeyType)
+		}
+		b, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case ed25519.PublicKey:
+		if rec.Spec.DKIM.KeyType != "ed25519" {
+			return "", fmt.Errorf("secret %s: expected a %s key, got an ed25519 key", secret.Name, rec.Spec.DKIM.KeyType)
+		}
+		return base64.StdEncoding.EncodeToString(k), nil
+	default:
+		return "", fmt.Errorf("secret %s: unsupported key type %T", secret.Name, key)
+	}
+}
+
+func parseCertificates(b []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+// secretRecords returns the records referencing the Secret
+func (r *DNSRecordReconciler) secretRecords(o client.Object) []reconcile.Request {
+	var recs dnsv1alpha1.DNSRecordList
+	if err := r.List(context.Background(), &recs, client.InNamespace(o.GetNamespace()), client.MatchingFields{secretKey: o.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list records", "secret", o.GetName())
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, v := range recs.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.Namespace, Name: v.Name}})
+	}
+	return reqs
+}
+
+func secretName(o client.Object) []string {
+	rec, ok := o.(*dnsv1alpha1.DNSRecord)
+	if !ok {
+		return nil
+	}
+	switch {
+	case rec.Spec.TLSA != nil && rec.Spec.TLSA.SecretName != "":
+		return []string{rec.Spec.TLSA.SecretName}
+	case rec.Spec.DKIM != nil && rec.Spec.DKIM.SecretName != "":
+		return []string{rec.Spec.DKIM.SecretName}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+var _ = Describe("Secret records", func() {
+	// secretReconciler returns a reconciler reading the Secret
+	secretReconciler := func(secret *corev1.Secret) *DNSRecordReconciler {
+		secret.Namespace = "default"
+		secret.Name = "secret"
+		return &DNSRecordReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()}
+	}
+
+	Describe("tlsaCertificate", func() {
+		var (
+			certs map[string]*x509.Certificate
+			pems  map[string][]byte
+		)
+
+		BeforeEach(func() {
+			ca, caKey, caPEM := newCertificate(nil, nil)
+			leaf, _, leafPEM := newCertificate(ca, caKey)
+			certs = map[string]*x509.Certificate{"ca": ca, "leaf": leaf}
+			pems = map[string][]byte{"ca": caPEM, "leaf": leafPEM, "chain": append(append([]byte(nil), leafPEM...), caPEM...)}
+		})
+
+		tlsa := func(usage uint8) *dnsv1alpha1.DNSRecord {
+			return &dnsv1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tlsa"},
+				Spec: dnsv1alpha1.DNSRecordSpec{TLSA: &dnsv1alpha1.TLSARecord{
+					Name:         "_443._tcp.example.org.",
+					Usage:        usage,
+					Selector:     1,
+					MatchingType: 1,
+					SecretName:   "secret",
+				}},
+			}
+		}
+
+		DescribeTable("selects the certificate matching the usage",
+			func(usage uint8, crt, caCrt, want string) {
+				data := map[string][]byte{corev1.TLSCertKey: pems[crt]}
+				if caCrt != "" {
+					data["ca.crt"] = pems[caCrt]
+				}
+				got, err := secretReconciler(&corev1.Secret{Type: corev1.SecretTypeTLS, Data: data}).tlsaCertificate(context.Background(), tlsa(usage))
+				Expect(err).ToNot(HaveOccurred())
+				dane, err := dns.CertificateToDANE(1, 1, certs[want])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got).To(Equal(dane))
+			},
+			Entry("PKIX-TA from the chain", uint8(0), "chain", "", "ca"),
+			Entry("PKIX-TA from ca.crt", uint8(0), "leaf", "ca", "ca"),
+			Entry("PKIX-EE", uint8(1), "chain", "ca", "leaf"),
+			Entry("DANE-TA from the chain", uint8(2), "chain", "", "ca"),
+			Entry("DANE-TA from ca.crt", uint8(2), "leaf", "ca", "ca"),
+			Entry("DANE-TA without a chain", uint8(2), "leaf", "", "leaf"),
+			Entry("DANE-EE", uint8(3), "chain", "ca", "leaf"),
+		)
+
+		It("rejects the secrets which are not TLS secrets", func() {
+			secret := &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: map[string][]byte{corev1.TLSCertKey: pems["chain"]}}
+			_, err := secretReconciler(secret).tlsaCertificate(context.Background(), tlsa(3))
+			Expect(err).To(MatchError("secret secret is not a kubernetes.io/tls secret"))
+		})
+
+		It("rejects the secrets without certificate", func() {
+			key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})
+			secret := &corev1.Secret{Type: corev1.SecretTypeTLS, Data: map[string][]byte{corev1.TLSCertKey: key}}
+			_, err := secretReconciler(secret).tlsaCertificate(context.Background(), tlsa(3))
+			Expect(err).To(MatchError("secret secret: no certificate found"))
+			secret = &corev1.Secret{Type: corev1.SecretTypeTLS, Data: map[string][]byte{corev1.TLSCertKey: pems["leaf"], "ca.crt": key}}
+			_, err = secretReconciler(secret).tlsaCertificate(context.Background(), tlsa(2))
+			Expect(err).To(MatchError("secret secret: ca.crt: no certificate found"))
+		})
+	})
+
+	Describe("dkimPublicKey", func() {
+		var (
+			keys map[string]crypto.Signer
+			want map[string]string
+		)
+
+		BeforeEach(func() {
+			rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			keys = map[string]crypto.Signer{"rsa": rsaKey, "ed25519": edKey}
+			rsaPub, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+			Expect(err).ToNot(HaveOccurred())
+			want = map[string]string{"rsa": base64.StdEncoding.EncodeToString(rsaPub), "ed25519": base64.StdEncoding.EncodeToString(edPub)}
+		})
+
+		// encode returns the key PEM encoded in the format
+		encode := func(key crypto.Signer, format string) []byte {
+			var (
+				block = &pem.Block{}
+				err   error
+			)
+			switch format {
+			case "pkcs1":
+				block.Type, block.Bytes = "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key.(*rsa.PrivateKey))
+			case "pkcs8":
+				block.Type = "PRIVATE KEY"
+				block.Bytes, err = x509.MarshalPKCS8PrivateKey(key)
+			case "pkix":
+				block.Type = "PUBLIC KEY"
+				block.Bytes, err = x509.MarshalPKIXPublicKey(key.Public())
+			}
+			Expect(err).ToNot(HaveOccurred())
+			return pem.EncodeToMemory(block)
+		}
+
+		dkim := func(keyType string) *dnsv1alpha1.DNSRecord {
+			return &dnsv1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dkim"},
+				Spec: dnsv1alpha1.DNSRecordSpec{DKIM: &dnsv1alpha1.DKIMRecord{
+					Name:       "mail._domainkey.example.org.",
+					KeyType:    keyType,
+					SecretName: "secret",
+					SecretKey:  "key.pem",
+				}},
+			}
+		}
+
+		DescribeTable("returns the public key",
+			func(keyType, format string) {
+				secret := &corev1.Secret{Data: map[string][]byte{"key.pem": encode(keys[keyType], format)}}
+				got, err := secretReconciler(secret).dkimPublicKey(context.Background(), dkim(keyType))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got).To(Equal(want[keyType]))
+			},
+			Entry("rsa pkcs1 private key", "rsa", "pkcs1"),
+			Entry("rsa pkcs8 private key", "rsa", "pkcs8"),
+			Entry("rsa pkix public key", "rsa", "pkix"),
+			Entry("ed25519 pkcs8 private key", "ed25519", "pkcs8"),
+			Entry("ed25519 pkix public key", "ed25519", "pkix"),
+		)
+
+		DescribeTable("rejects the keys not matching the key type",
+			func(key, format, keyType, err string) {
+				secret := &corev1.Secret{Data: map[string][]byte{"key.pem": encode(keys[key], format)}}
+				_, got := secretReconciler(secret).dkimPublicKey(context.Background(), dkim(keyType))
+				Expect(got).To(MatchError(err))
+			},
+			Entry("rsa private key", "rsa", "pkcs1", "ed25519", "secret secret: expected a ed25519 key, got a rsa key"),
+			Entry("rsa public key", "rsa", "pkix", "ed25519", "secret secret: expected a ed25519 key, got a rsa key"),
+			Entry("ed25519 private key", "ed25519", "pkcs8", "rsa", "secret secret: expected a rsa key, got an ed25519 key"),
+			Entry("ed25519 public key", "ed25519", "pkix", "rsa", "secret secret: expected a rsa key, got an ed25519 key"),
+		)
+
+		It("rejects the secrets without PEM encoded key", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{"key.pem": []byte("key")}}
+			_, err := secretReconciler(secret).dkimPublicKey(context.Background(), dkim("rsa"))
+			Expect(err).To(MatchError("secret secret: no PEM encoded key found in key.pem"))
+			_, certKey, _ := newCertificate(nil, nil)
+			b, err := x509.MarshalECPrivateKey(certKey)
+			Expect(err).ToNot(HaveOccurred())
+			secret = &corev1.Secret{Data: map[string][]byte{"key.pem": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})}}
+			_, err = secretReconciler(secret).dkimPublicKey(context.Background(), dkim("rsa"))
+			Expect(err).To(MatchError("secret secret: unsupported PEM block type: EC PRIVATE KEY"))
+		})
+	})
+})
+
+// newCertificate returns a certificate signed by the parent's key, or a self signed CA certificate when parent is nil,
+// with its key and its PEM encoded form
+func newCertificate(parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "example.org"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		tmpl.Subject.CommonName = "ca"
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	b, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(b)
+	Expect(err).ToNot(HaveOccurred())
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b})
+}
@@ -18,12 +18,10 @@ package controllers
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/go-logr/logr"
 	"github.com/weppos/publicsuffix-go/publicsuffix"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
 )
 
 // ServiceReconciler reconciles a Service object
@@ -38,6 +37,12 @@ type ServiceReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// DefaultTTL is the records TTL when neither the resource, its namespace nor its zone set one
+	DefaultTTL uint32
+	// Resolver looks up the hostname targets flattened at a zone apex, net.DefaultResolver if nil
+	Resolver Resolver
+
+	recorder recorder.Recorder
 }
 
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
@@ -89,51 +94,38 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	var ttl uint32
-	if v, ok := svc.Annotations[TTLAnnotation]; ok {
-		i, err := strconv.ParseUint(v, 10, 32)
-		if err != nil {
-			log.Error(err, "invalid TTL annotation, using defaults: 3600")
-		} else {
-			ttl = uint32(i)
-		}
+	ttl := annotationTTL(log, svc.Annotations)
+	t, family := annotatedTargets(log, r.recorder, &svc, loadBalancerTargets(r.recorder, &svc, svc.Status.LoadBalancer))
+	t, refresh, err := resolveTargets(ctx, r.Client, r.Resolver, hostname, t)
+	if err != nil {
+		log.Error(err, "unable to resolve targets", "host", hostname)
+		return ctrl.Result{}, err
 	}
+	t = t.only(family)
 	var want dnsv1alpha1.DNSRecordList
-	var ips []string
-	for _, v := range svc.Status.LoadBalancer.Ingress {
-		if v.IP != "" {
-			ips = append(ips, v.IP)
+	for _, rec := range targetRecords(&svc, "svc", ServiceAnnotation, hostname, ttl, t) {
+		if v, ok := svc.Annotations[PTRAnnotation]; ok && rec.Spec.CNAME == nil {
+			rec.Annotations[PTRAnnotation] = v
 		}
-	}
-	for i, vv := range ips {
-		rec := dnsv1alpha1.DNSRecord{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      recordName(svc.Name, "svc", hostname, i),
-				Namespace: svc.Namespace,
-				Annotations: map[string]string{
-					ServiceAnnotation: svc.Name,
-				},
-			},
-			Spec: dnsv1alpha1.DNSRecordSpec{
-				A: &dnsv1alpha1.ARecord{
-					Name:   hostname,
-					Ttl:    ttl,
-					Target: vv,
-				},
-			},
+		if err := defaultRecord(ctx, r.Client, &rec, r.DefaultTTL); err != nil {
+			return ctrl.Result{}, err
 		}
-		rec.Default()
 		if err := ctrl.SetControllerReference(&svc, &rec, r.Scheme); err != nil {
 			return ctrl.Result{}, err
 		}
 		want.Items = append(want.Items, rec)
 	}
 
-	return reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+	res, err := reconcileChildRecords(ctrl.LoggerInto(ctx, log), r.Client, got, want)
+	if err == nil && refresh {
+		res.RequeueAfter = flattenRefresh
+	}
+	return res, err
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = recorder.New(mgr.GetEventRecorderFor("Service"))
 	fn := extractValue("core/v1", "Service")
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Service{}, ownerKey, fn); err != nil {
 		return err
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/miekg/dns"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
+)
+
+// flattenRefresh is the interval at which the flattened hostname targets are resolved again
+const flattenRefresh = 5 * time.Minute
+
+// Resolver looks up the addresses of the hostname targets flattened at a zone apex, net.Resolver implements it
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// targets are the addresses or the hostname a source's records point to
+type targets struct {
+	IPv4     []string
+	IPv6     []string
+	Hostname string
+}
+
+func (t targets) empty() bool {
+	return len(t.IPv4) == 0 && len(t.IPv6) == 0 && t.Hostname == ""
+}
+
+func (t *targets) addIP(v string) bool {
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return false
+	}
+	if ip.To4() != nil {
+		t.IPv4 = append(t.IPv4, ip.String())
+	} else {
+		t.IPv6 = append(t.IPv6, ip.String())
+	}
+	return true
+}
+
+// only returns the targets restricted to the IP family, all of them if the family is empty
+func (t targets) only(family corev1.IPFamily) targets {
+	switch family {
+	case corev1.IPv4Protocol:
+		t.IPv6 = nil
+	case corev1.IPv6Protocol:
+		t.IPv4 = nil
+	}
+	return t
+}
+
+// ipFamily returns the IP family set by the IPFamilyAnnotation, if any
+func ipFamily(annotations map[string]string) (corev1.IPFamily, error) {
+	v, ok := annotations[IPFamilyAnnotation]
+	if !ok {
+		return "", nil
+	}
+	switch strings.ToLower(v) {
+	case "ipv4":
+		return corev1.IPv4Protocol, nil
+	case "ipv6":
+		return corev1.IPv6Protocol, nil
+	}
+	return "", fmt.Errorf("invalid ip family %q: expected IPv4 or IPv6", v)
+}
+
+// annotatedTargets returns the targets overridden by the source's TargetAnnotation, and the IP family set by its
+// IPFamilyAnnotation, the invalid annotations are ignored and reported as Warning events on the source
+func annotatedTargets(log logr.Logger, rec recorder.Recorder, o client.Object, t targets) (targets, corev1.IPFamily) {
+	annotations := o.GetAnnotations()
+	if v, ok := annotations[TargetAnnotation]; ok {
+		if at, err := parseTarget(v); err != nil {
+			log.Error(err, "invalid target annotation, using the load balancer status")
+			rec.Warn(o, "InvalidTarget", err.Error())
+		} else {
+			t = at
+		}
+	}
+	family, err := ipFamily(annotations)
+	if err != nil {
+		log.Error(err, "invalid ip family annotation, publishing both families")
+		rec.Warn(o, "InvalidIPFamily", err.Error())
+	}
+	return t, family
+}
+
+// loadBalancerTargets returns the load balancer status addresses, or its hostname
+// for the load balancers reporting only hostnames
+func loadBalancerTargets(rec recorder.Recorder, o client.Object, status corev1.LoadBalancerStatus) targets {
+	var t targets
+	var hosts []string
+	for _, v := range status.Ingress {
+		if v.IP != "" {
+			t.addIP(v.IP)
+		} else if v.Hostname != "" {
+			hosts = append(hosts, v.Hostname)
+		}
+	}
+	return hostnameTarget(rec, o, t, hosts)
+}
+
+// hostnameTarget sets the first hostname as the target when there is no address, as a CNAME can only have
+// a single target, the other hostnames are ignored and reported as a Warning event on the source
+func hostnameTarget(rec recorder.Recorder, o client.Object, t targets, hosts []string) targets {
+	if !t.empty() || len(hosts) == 0 {
+		return t
+	}
+	t.Hostname = dns.Fqdn(strings.ToLower(hosts[0]))
+	if len(hosts) > 1 {
+		rec.Warnf(o, "IgnoredHostnames", "only the first hostname %s is published, ignoring %s", hosts[0], strings.Join(hosts[1:], ", "))
+	}
+	return t
+}
+
+// resolveTargets flattens the hostname target into its addresses when the host is a zone apex,
+// where a CNAME is not allowed, it returns true if the targets were flattened and must be refreshed.
+// The hostname is looked up with res, or net.DefaultResolver if nil
+func resolveTargets(ctx context.Context, c client.Reader, res Resolver, host string, t targets) (targets, bool, error) {
+	if t.Hostname == "" {
+		return t, false, nil
+	}
+	apex, err := isApex(ctx, c, host)
+	if err != nil || !apex {
+		return t, false, err
+	}
+	if res == nil {
+		res = net.DefaultResolver
+	}
+	addrs, err := res.LookupIPAddr(ctx, t.Hostname)
+	if err != nil {
+		return targets{}, false, fmt.Errorf("flatten %s: %w", t.Hostname, err)
+	}
+	var ft targets
+	for _, v := range addrs {
+		ft.addIP(v.IP.String())
+	}
+	if ft.empty() {
+		return targets{}, false, fmt.Errorf("flatten %s: no address found", t.Hostname)
+	}
+	return ft, true, nil
+}
+
+// isApex returns true if the host is the apex of its declared DNSZone, or of its public suffix zone
+func isApex(ctx context.Context, c client.Reader, host string) (bool, error) {
+	host = dns.Fqdn(strings.ToLower(host))
+	z, err := dnsv1alpha1.ZoneFor(ctx, c, host)
+	if err != nil {
+		return false, err
+	}
+	return z == host, nil
+}
+
+// parseTarget parses the TargetAnnotation value, either a comma separated list of IP addresses or a single hostname
+func parseTarget(v string) (targets, error) {
+	var t targets
+	var hosts []string
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p == "" || t.addIP(p) {
+			continue
+		}
+		if _, ok := dns.IsDomainName(p); !ok || dns.CountLabel(p) < 2 {
+			return targets{}, fmt.Errorf("invalid target %q: expected an IP address or a hostname", p)
+		}
+		hosts = append(hosts, dns.Fqdn(strings.ToLower(p)))
+	}
+	switch {
+	case len(hosts) > 1:
+		return targets{}, fmt.Errorf("invalid target %q: only one hostname can be set", v)
+	case len(hosts) == 1 && (len(t.IPv4) != 0 || len(t.IPv6) != 0):
+		return targets{}, fmt.Errorf("invalid target %q: addresses and hostname cannot be mixed", v)
+	case len(hosts) == 1:
+		t.Hostname = hosts[0]
+	}
+	if t.empty() {
+		return targets{}, errors.New("empty target")
+	}
+	return t, nil
+}
+
+// targetRecords returns the records publishing the host with the targets: an A and an AAAA record
+// for the addresses, or a CNAME record for the hostname
+func targetRecords(o client.Object, typ, annotation, host string, ttl uint32, t targets) []dnsv1alpha1.DNSRecord {
+	record := func(typ string, spec dnsv1alpha1.DNSRecordSpec) dnsv1alpha1.DNSRecord {
+		return dnsv1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      recordName(o.GetName(), typ, host),
+				Namespace: o.GetNamespace(),
+				Annotations: map[string]string{
+					annotation: o.GetName(),
+				},
+			},
+			Spec: spec,
+		}
+	}
+	if t.Hostname != "" {
+		return []dnsv1alpha1.DNSRecord{record(typ+"-cname", dnsv1alpha1.DNSRecordSpec{
+			CNAME: &dnsv1alpha1.CNAMERecord{Name: host, Ttl: ttl, Target: t.Hostname},
+		})}
+	}
+	var recs []dnsv1alpha1.DNSRecord
+	if len(t.IPv4) != 0 {
+		recs = append(recs, record(typ, dnsv1alpha1.DNSRecordSpec{
+			A: &dnsv1alpha1.ARecord{Name: host, Ttl: ttl, Targets: t.IPv4},
+		}))
+	}
+	if len(t.IPv6) != 0 {
+		recs = append(recs, record(typ+"-aaaa", dnsv1alpha1.DNSRecordSpec{
+			AAAA: &dnsv1alpha1.AAAARecord{Name: host, Ttl: ttl, Targets: t.IPv6},
+		}))
+	}
+	return recs
+}
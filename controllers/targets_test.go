@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/recorder"
+)
+
+// fakeResolver resolves the hostnames from its addresses, and counts the lookups
+type fakeResolver struct {
+	addrs   map[string][]string
+	lookups int
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	r.lookups++
+	v, ok := r.addrs[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var out []net.IPAddr
+	for _, a := range v {
+		out = append(out, net.IPAddr{IP: net.ParseIP(a)})
+	}
+	return out, nil
+}
+
+// recordedEvents closes the recorder and returns its recorded events
+func recordedEvents(r *record.FakeRecorder) []string {
+	close(r.Events)
+	var out []string
+	for v := range r.Events {
+		out = append(out, v)
+	}
+	return out
+}
+
+var _ = Describe("Targets", func() {
+	DescribeTable("parses the target annotation",
+		func(v string, want targets, err string) {
+			got, e := parseTarget(v)
+			if err != "" {
+				Expect(e).To(MatchError(ContainSubstring(err)))
+				return
+			}
+			Expect(e).ToNot(HaveOccurred())
+			Expect(got).To(Equal(want))
+		},
+		Entry("an IPv4 address", "192.0.2.1", targets{IPv4: []string{"192.0.2.1"}}, ""),
+		Entry("IPv4 and IPv6 addresses", "192.0.2.1, 2001:db8::1,192.0.2.2",
+			targets{IPv4: []string{"192.0.2.1", "192.0.2.2"}, IPv6: []string{"2001:db8::1"}}, ""),
+		Entry("a hostname", "LB.Example.org", targets{Hostname: "lb.example.org."}, ""),
+		Entry("an absolute hostname", "lb.example.org.", targets{Hostname: "lb.example.org."}, ""),
+		Entry("an empty value", "", targets{}, "empty target"),
+		Entry("only separators", " , ,", targets{}, "empty target"),
+		Entry("a hostname and an address", "lb.example.org,192.0.2.1", targets{}, "addresses and hostname cannot be mixed"),
+		Entry("several hostnames", "lb1.example.org,lb2.example.org", targets{}, "only one hostname can be set"),
+		Entry("a single label", "localhost", targets{}, `invalid target "localhost"`),
+		Entry("an invalid value", "192.0.2.1,not a host", targets{}, `invalid target "not a host"`),
+	)
+
+	DescribeTable("overrides the targets with the annotations",
+		func(annotations map[string]string, want targets, family corev1.IPFamily, warnings ...string) {
+			fake := record.NewFakeRecorder(10)
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "service", Annotations: annotations}}
+			lb := targets{IPv4: []string{"192.0.2.1"}}
+			got, f := annotatedTargets(ctrl.Log, recorder.New(fake), svc, lb)
+			Expect(got).To(Equal(want))
+			Expect(f).To(Equal(family))
+			events := recordedEvents(fake)
+			Expect(events).To(HaveLen(len(warnings)))
+			for i, v := range warnings {
+				Expect(events[i]).To(HavePrefix(v))
+			}
+		},
+		Entry("no annotations", nil, targets{IPv4: []string{"192.0.2.1"}}, corev1.IPFamily("")),
+		Entry("addresses", map[string]string{TargetAnnotation: "192.0.2.10,2001:db8::10"},
+			targets{IPv4: []string{"192.0.2.10"}, IPv6: []string{"2001:db8::10"}}, corev1.IPFamily("")),
+		Entry("a hostname", map[string]string{TargetAnnotation: "lb.example.org"},
+			targets{Hostname: "lb.example.org."}, corev1.IPFamily("")),
+		Entry("an IP family", map[string]string{IPFamilyAnnotation: "IPv6"},
+			targets{IPv4: []string{"192.0.2.1"}}, corev1.IPv6Protocol),
+		Entry("an empty target", map[string]string{TargetAnnotation: ""},
+			targets{IPv4: []string{"192.0.2.1"}}, corev1.IPFamily(""), "Warning InvalidTarget empty target"),
+		Entry("an invalid target", map[string]string{TargetAnnotation: "lb1.example.org,lb2.example.org"},
+			targets{IPv4: []string{"192.0.2.1"}}, corev1.IPFamily(""), "Warning InvalidTarget invalid target"),
+		Entry("an invalid IP family", map[string]string{IPFamilyAnnotation: "ipv5"},
+			targets{IPv4: []string{"192.0.2.1"}}, corev1.IPFamily(""), `Warning InvalidIPFamily invalid ip family "ipv5"`),
+		Entry("an invalid target and IP family", map[string]string{TargetAnnotation: "localhost", IPFamilyAnnotation: "dual"},
+			targets{IPv4: []string{"192.0.2.1"}}, corev1.IPFamily(""), "Warning InvalidTarget", "Warning InvalidIPFamily"),
+	)
+
+	DescribeTable("reads the load balancer status",
+		func(ingress []corev1.LoadBalancerIngress, want targets, events ...string) {
+			fake := record.NewFakeRecorder(10)
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "service"}}
+			Expect(loadBalancerTargets(recorder.New(fake), svc, corev1.LoadBalancerStatus{Ingress: ingress})).To(Equal(want))
+			Expect(recordedEvents(fake)).To(ConsistOf(events))
+		},
+		Entry("no ingress", nil, targets{}),
+		Entry("addresses", []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}, {IP: "2001:db8::1"}},
+			targets{IPv4: []string{"192.0.2.1"}, IPv6: []string{"2001:db8::1"}}),
+		Entry("a hostname", []corev1.LoadBalancerIngress{{Hostname: "LB.Example.org"}},
+			targets{Hostname: "lb.example.org."}),
+		Entry("addresses and hostnames", []corev1.LoadBalancerIngress{{Hostname: "lb.example.org"}, {IP: "192.0.2.1"}},
+			targets{IPv4: []string{"192.0.2.1"}}),
+		Entry("several hostnames", []corev1.LoadBalancerIngress{{Hostname: "lb1.example.org"}, {Hostname: "lb2.example.org"}, {Hostname: "lb3.example.org"}},
+			targets{Hostname: "lb1.example.org."},
+			"Warning IgnoredHostnames only the first hostname lb1.example.org is published, ignoring lb2.example.org, lb3.example.org"),
+	)
+
+	Context("with declared zones", func() {
+		var c client.Client
+
+		BeforeEach(func() {
+			c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&dnsv1alpha1.DNSZone{ObjectMeta: metav1.ObjectMeta{Name: "example"}, Spec: dnsv1alpha1.DNSZoneSpec{Origin: "example.org."}},
+				&dnsv1alpha1.DNSZone{ObjectMeta: metav1.ObjectMeta{Name: "dev"}, Spec: dnsv1alpha1.DNSZoneSpec{Origin: "dev.example.org."}},
+			).Build()
+		})
+
+		DescribeTable("finds the zone apexes",
+			func(host string, want bool) {
+				got, err := isApex(context.Background(), c, host)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got).To(Equal(want))
+			},
+			Entry("a zone origin", "example.org", true),
+			Entry("a mixed case zone origin", "Dev.Example.org.", true),
+			Entry("a zone host", "www.example.org.", false),
+			Entry("outside of the zones", "example.com.", false),
+		)
+
+		DescribeTable("flattens the hostname targets at the zone apexes",
+			func(host string, t targets, want targets, flattened bool, lookups int, err string) {
+				res := &fakeResolver{addrs: map[string][]string{"lb.example.net.": {"192.0.2.1", "2001:db8::1"}, "empty.example.net.": nil}}
+				got, f, e := resolveTargets(context.Background(), c, res, host, t)
+				Expect(res.lookups).To(Equal(lookups))
+				if err != "" {
+					Expect(e).To(MatchError(err))
+					return
+				}
+				Expect(e).ToNot(HaveOccurred())
+				Expect(got).To(Equal(want))
+				Expect(f).To(Equal(flattened))
+			},
+			Entry("addresses", "example.org.", targets{IPv4: []string{"192.0.2.10"}}, targets{IPv4: []string{"192.0.2.10"}}, false, 0, ""),
+			Entry("a hostname out of the apex", "www.example.org.", targets{Hostname: "lb.example.net."}, targets{Hostname: "lb.example.net."}, false, 0, ""),
+			Entry("a hostname at the apex", "example.org.", targets{Hostname: "lb.example.net."},
+				targets{IPv4: []string{"192.0.2.1"}, IPv6: []string{"2001:db8::1"}}, true, 1, ""),
+			Entry("an unknown hostname", "example.org.", targets{Hostname: "unknown.example.net."}, targets{}, false, 1, "flatten unknown.example.net.: no such host"),
+			Entry("a hostname without address", "dev.example.org.", targets{Hostname: "empty.example.net."}, targets{}, false, 1, "flatten empty.example.net.: no address found"),
+		)
+	})
+})
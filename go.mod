@@ -22,6 +22,7 @@ require (
 	github.com/weppos/publicsuffix-go v0.13.0
 	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.21.0
+	golang.org/x/net v0.0.0-20220722155237-a158d28d115b
 	golang.org/x/sys v0.0.0-20220928140112-f11e5e49a4ec // indirect
 	k8s.io/api v0.25.0
 	k8s.io/apimachinery v0.25.0
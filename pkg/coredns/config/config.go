@@ -22,13 +22,14 @@ import (
 )
 
 type Config struct {
-	Forward         []string
-	Log             bool
-	Errors          bool
-	Metrics         bool
-	Cache           int
-	Any             bool
-	ExternalAddress string
+	Forward []string
+	Log     bool
+	Errors  bool
+	Metrics bool
+	Cache   int
+	Any     bool
+	// ExternalAddresses are the IPv4 and IPv6 addresses published as the default name server glue records
+	ExternalAddresses []string
 }
 
 func (c Config) Render() (string, error) {
@@ -41,7 +42,7 @@ func (c Config) Render() (string, error) {
 
 var configTemplate = template.Must(template.New("corefile").Parse(`
 .:53 {
-	k8s_dns{{- if .ExternalAddress }} {{ .ExternalAddress }}{{- end }}
+	k8s_dns{{- range .ExternalAddresses }} {{ . }}{{- end }}
 {{- if .Any }}
 	any
 {{- end }}
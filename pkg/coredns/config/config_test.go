@@ -22,12 +22,22 @@ func TestConfig(t *testing.T) {
 		},
 		{
 			config: Config{
-				ExternalAddress: "10.0.1.0",
+				ExternalAddresses: []string{"10.0.1.0"},
 			},
 			want: `
 .:53 {
 	k8s_dns 10.0.1.0
 }
+`,
+		},
+		{
+			config: Config{
+				ExternalAddresses: []string{"10.0.1.0", "2001:db8::1"},
+			},
+			want: `
+.:53 {
+	k8s_dns 10.0.1.0 2001:db8::1
+}
 `,
 		},
 		{
@@ -43,11 +43,10 @@ func init() {
 type CRDS struct {
 	Next     plugin.Handler
 	provider Provider
-	external string
 }
 
-func New(external string) (*CRDS, error) {
-	provider, err := NewProvider(context.Background(), net.ParseIP(external))
+func New(external ...net.IP) (*CRDS, error) {
+	provider, err := NewProvider(context.Background(), external...)
 	if err != nil {
 		return nil, err
 	}
@@ -118,20 +117,11 @@ func (p *CRDS) Name() string {
 }
 
 func setup(c *caddy.Controller) error {
-	var external string
-	for c.NextBlock() {
-		switch c.Val() {
-		case "external":
-			args := c.RemainingArgs()
-			if len(args) == 0 {
-				return c.ArgErr()
-			}
-			external = args[0]
-		default:
-			return c.Errf("unknown property '%s'", c.Val())
-		}
+	external, err := parse(c)
+	if err != nil {
+		return err
 	}
-	p, err := New(external)
+	p, err := New(external...)
 	if err != nil {
 		return plugin.Error(name, err)
 	}
@@ -141,3 +131,32 @@ func setup(c *caddy.Controller) error {
 	})
 	return nil
 }
+
+// parse returns the external addresses, either the plugin's arguments or the external property ones
+func parse(c *caddy.Controller) ([]net.IP, error) {
+	var args []string
+	for c.Next() {
+		args = append(args, c.RemainingArgs()...)
+		for c.NextBlock() {
+			switch c.Val() {
+			case "external":
+				v := c.RemainingArgs()
+				if len(v) == 0 {
+					return nil, c.ArgErr()
+				}
+				args = append(args, v...)
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+	}
+	var external []net.IP
+	for _, v := range args {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return nil, c.Errf("invalid external address '%s'", v)
+		}
+		external = append(external, ip)
+	}
+	return external, nil
+}
@@ -3,12 +3,17 @@ package crds
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 
+	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/plugin/pkg/dnstest"
 	"github.com/coredns/coredns/plugin/test"
 	"github.com/miekg/dns"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/record"
 )
 
 var miekAuth = []dns.RR{
@@ -174,3 +179,255 @@ func TestCRDS(t *testing.T) {
 		}
 	}
 }
+
+var delegationTests = []test.Case{
+	{
+		Qname: "www.team.example.org.", Qtype: dns.TypeA,
+		Ns: []dns.RR{
+			test.NS("team.example.org.	3600	IN	NS	ns1.team.example.org."),
+			test.NS("team.example.org.	3600	IN	NS	ns2.other.org."),
+		},
+		Extra: []dns.RR{
+			test.A("ns1.team.example.org.	3600	IN	A	10.0.0.53"),
+		},
+	},
+	{
+		Qname: "team.example.org.", Qtype: dns.TypeNS,
+		Ns: []dns.RR{
+			test.NS("team.example.org.	3600	IN	NS	ns1.team.example.org."),
+			test.NS("team.example.org.	3600	IN	NS	ns2.other.org."),
+		},
+		Extra: []dns.RR{
+			test.A("ns1.team.example.org.	3600	IN	A	10.0.0.53"),
+		},
+	},
+	{
+		Qname: "www.example.org.", Qtype: dns.TypeA,
+		Answer: []dns.RR{
+			test.A("www.example.org.	3600	IN	A	10.0.0.1"),
+		},
+		Ns: []dns.RR{
+			test.NS("example.org.	3600	IN	NS	ns0.dns.example.org."),
+		},
+	},
+}
+
+func TestCRDSDelegation(t *testing.T) {
+	recs := []v1alpha1.DNSRecord{
+		{Spec: v1alpha1.DNSRecordSpec{NS: &v1alpha1.NSRecord{Name: "team.example.org", Targets: []string{"ns1.team.example.org", "ns2.other.org"}}}},
+		{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "ns1.team.example.org", Target: "10.0.0.53"}}},
+		{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.org", Target: "10.0.0.1"}}},
+	}
+	records := make(map[string]dns.RR)
+	for _, v := range recs {
+		v.Default()
+		rrs, err := record.ToRR(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rr := range rrs {
+			records[rr.String()] = rr
+		}
+	}
+	prov := &provider{records: records}
+	if err := prov.sync(); err != nil {
+		t.Fatal(err)
+	}
+	p := &CRDS{provider: prov}
+	p.Next = test.NextHandler(dns.RcodeSuccess, nil)
+	ctx := context.TODO()
+	for i, tc := range delegationTests {
+		r := tc.Msg()
+		w := dnstest.NewRecorder(&test.ResponseWriter{})
+		if _, err := p.ServeDNS(ctx, w, r); err != nil {
+			t.Fatalf("Test %d expected no error, got %v", i, err)
+		}
+		if w.Msg == nil {
+			t.Fatalf("Test %d, got nil message and no error for %q", i, r.Question[0].Name)
+		}
+		if err := test.SortAndCheck(w.Msg, tc); err != nil {
+			t.Errorf("Test %d: %v", i, err)
+		}
+		if tc.Answer == nil && w.Msg.Authoritative {
+			t.Errorf("Test %d: expected non authoritative referral", i)
+		}
+	}
+}
+
+func TestCRDSHTTPS(t *testing.T) {
+	rec := v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{HTTPS: &v1alpha1.SVCBRecord{
+		Name:     "www.example.org",
+		Priority: 1,
+		Alpn:     []string{"h2", "h3"},
+		IPv4Hint: []string{"10.0.0.1"},
+	}}}
+	rec.Default()
+	rrs, err := record.ToRR(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := &provider{records: map[string]dns.RR{rrs[0].String(): rrs[0]}}
+	if err := prov.sync(); err != nil {
+		t.Fatal(err)
+	}
+	p := &CRDS{provider: prov}
+	p.Next = test.NextHandler(dns.RcodeSuccess, nil)
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.org.", dns.TypeHTTPS)
+	w := dnstest.NewRecorder(&test.ResponseWriter{})
+	if _, err := p.ServeDNS(context.TODO(), w, m); err != nil {
+		t.Fatal(err)
+	}
+	if w.Msg == nil || len(w.Msg.Answer) != 1 {
+		t.Fatalf("expected a single answer, got %v", w.Msg)
+	}
+	if got, want := w.Msg.Answer[0].String(), `www.example.org.	3600	IN	HTTPS	1 . alpn="h2,h3" ipv4hint="10.0.0.1"`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCRDSZones(t *testing.T) {
+	zone := &v1alpha1.DNSZone{Spec: v1alpha1.DNSZoneSpec{
+		Origin:      "example.org",
+		Nameservers: []string{"ns1.example.org", "ns2.example.net"},
+		SOA:         v1alpha1.SOA{Mbox: "admin.example.org"},
+	}}
+	zone.Default()
+	recs := []v1alpha1.DNSRecord{
+		{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.org", Target: "10.0.0.1"}}},
+		{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.com", Target: "10.0.0.2"}}},
+	}
+	records := make(map[string]dns.RR)
+	for _, v := range recs {
+		v.Default()
+		rrs, err := record.ToRR(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rr := range rrs {
+			records[rr.String()] = rr
+		}
+	}
+	prov := &provider{records: records, dnsZones: map[string]*v1alpha1.DNSZone{"example": zone}}
+	if err := prov.sync(); err == nil {
+		t.Fatal("expected an error for the record outside of the declared zones")
+	}
+	if len(prov.zones.Names) != 1 || prov.zones.Names[0] != "example.org." {
+		t.Fatalf("expected only the declared zone, got %v", prov.zones.Names)
+	}
+	soa := prov.zones.Z["example.org."].SOA
+	if soa.Mbox != "admin.example.org." || soa.Ns != "ns1.example.org." || soa.Refresh != v1alpha1.DefaultRefresh || soa.Serial == 0 {
+		t.Errorf("unexpected SOA: %v", soa)
+	}
+	p := &CRDS{provider: prov}
+	p.Next = test.NextHandler(dns.RcodeRefused, nil)
+	tc := test.Case{
+		Qname: "www.example.org.", Qtype: dns.TypeA,
+		Answer: []dns.RR{
+			test.A("www.example.org.	3600	IN	A	10.0.0.1"),
+		},
+		Ns: []dns.RR{
+			test.NS("example.org.	3600	IN	NS	ns1.example.org."),
+			test.NS("example.org.	3600	IN	NS	ns2.example.net."),
+		},
+	}
+	w := dnstest.NewRecorder(&test.ResponseWriter{})
+	if _, err := p.ServeDNS(context.TODO(), w, tc.Msg()); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SortAndCheck(w.Msg, tc); err != nil {
+		t.Error(err)
+	}
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.com.", dns.TypeA)
+	if rcode, _ := p.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m); rcode != dns.RcodeRefused {
+		t.Errorf("expected the query to be passed to the next plugin, got rcode %d", rcode)
+	}
+}
+
+func TestCRDSGlue(t *testing.T) {
+	rec := v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.org", Target: "10.0.0.1"}}}
+	rec.Default()
+	rrs, err := record.ToRR(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := &provider{
+		records:           map[string]dns.RR{rrs[0].String(): rrs[0]},
+		externalAddresses: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+	}
+	if err := prov.sync(); err != nil {
+		t.Fatal(err)
+	}
+	p := &CRDS{provider: prov}
+	for _, tc := range []test.Case{
+		{Qname: "ns0.dns.example.org.", Qtype: dns.TypeA, Answer: []dns.RR{test.A("ns0.dns.example.org.	3600	IN	A	192.0.2.1")}},
+		{Qname: "ns0.dns.example.org.", Qtype: dns.TypeAAAA, Answer: []dns.RR{test.AAAA("ns0.dns.example.org.	3600	IN	AAAA	2001:db8::1")}},
+	} {
+		w := dnstest.NewRecorder(&test.ResponseWriter{})
+		if _, err := p.ServeDNS(context.TODO(), w, tc.Msg()); err != nil {
+			t.Fatal(err)
+		}
+		if len(w.Msg.Answer) != 1 || w.Msg.Answer[0].String() != tc.Answer[0].String() {
+			t.Errorf("expected %v, got %v", tc.Answer, w.Msg.Answer)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "k8s_dns"},
+		{input: "k8s_dns 10.0.0.1 2001:db8::1", want: []string{"10.0.0.1", "2001:db8::1"}},
+		{input: "k8s_dns {\n external 10.0.0.1\n}", want: []string{"10.0.0.1"}},
+		{input: "k8s_dns example.org", wantErr: true},
+		{input: "k8s_dns {\n unknown\n}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parse(caddy.NewTestController("dns", tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ips []string
+			for _, v := range got {
+				ips = append(ips, v.String())
+			}
+			if fmt.Sprint(ips) != fmt.Sprint(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ips)
+			}
+		})
+	}
+}
+
+func TestProviderOwners(t *testing.T) {
+	rec := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.org", Targets: []string{"10.0.0.1", "10.0.0.2"}}}}
+	rec.Default()
+	rrs, err := record.ToRR(*rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crec := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "www.example.org", Targets: []string{"10.0.0.1"}}}}
+	crec.Default()
+	crrs, err := record.ToRR(*crec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := &provider{records: map[string]dns.RR{}, owners: map[string]map[string]struct{}{}}
+	prov.add("DNSRecord default/www", rec, rrs)
+	prov.add("ClusterDNSRecord www", crec, crrs)
+	if len(prov.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(prov.records))
+	}
+	prov.remove("DNSRecord default/www")
+	if _, ok := prov.records[crrs[0].String()]; !ok || len(prov.records) != 1 {
+		t.Fatalf("expected the record shared with the cluster record to be kept, got %v", prov.records)
+	}
+	prov.remove("ClusterDNSRecord www")
+	if len(prov.records) != 0 || len(prov.owners) != 0 {
+		t.Fatalf("expected no record, got %v", prov.records)
+	}
+}
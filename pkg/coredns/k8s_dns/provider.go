@@ -21,24 +21,26 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/coredns/coredns/plugin"
 	"github.com/coredns/coredns/plugin/file"
 	"github.com/coredns/coredns/plugin/pkg/dnsutil"
 	"github.com/miekg/dns"
-	"github.com/weppos/publicsuffix-go/publicsuffix"
 	"go.uber.org/multierr"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/kubernetes/scheme"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	toolscache "k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"go.linka.cloud/k8s/dns/api/v1alpha1"
 	"go.linka.cloud/k8s/dns/pkg/ptr"
 	"go.linka.cloud/k8s/dns/pkg/record"
+	dnszone "go.linka.cloud/k8s/dns/pkg/zone"
 )
 
 const (
@@ -53,19 +55,26 @@ type Provider interface {
 }
 
 type provider struct {
-	ctx             context.Context
-	cache           cache.Cache
-	zones           file.Zones
-	records         map[string]dns.RR
-	externalAddress net.IP
-	mu              sync.RWMutex
+	ctx     context.Context
+	cache   cache.Cache
+	zones   file.Zones
+	records map[string]dns.RR
+	// owners are the DNSRecords and ClusterDNSRecords defining the records, by record,
+	// a record is served as long as one of its owners defines it
+	owners map[string]map[string]struct{}
+	// dnsZones are the declared DNSZones by object name
+	dnsZones map[string]*v1alpha1.DNSZone
+	// externalAddresses are the default name server glue addresses
+	externalAddresses []net.IP
+	mu                sync.RWMutex
+	serial            uint32
 
 	hostmaster string
 	ttl        uint32
 	apex       string
 }
 
-func NewProvider(ctx context.Context, externalAddress net.IP) (Provider, error) {
+func NewProvider(ctx context.Context, externalAddresses ...net.IP) (Provider, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -83,11 +92,13 @@ func NewProvider(ctx context.Context, externalAddress net.IP) (Provider, error)
 		zones: file.Zones{
 			Z: map[string]*file.Zone{},
 		},
-		records:         make(map[string]dns.RR),
-		hostmaster:      defaultHostmaster,
-		ttl:             defaultTTL,
-		apex:            defaultApex,
-		externalAddress: externalAddress,
+		records:           make(map[string]dns.RR),
+		owners:            make(map[string]map[string]struct{}),
+		dnsZones:          make(map[string]*v1alpha1.DNSZone),
+		hostmaster:        defaultHostmaster,
+		ttl:               defaultTTL,
+		apex:              defaultApex,
+		externalAddresses: externalAddresses,
 	}
 	return p, nil
 }
@@ -113,20 +124,43 @@ func (p *provider) sync() error {
 	if p.apex == "" {
 		p.apex = defaultApex
 	}
+	// the zones are rebuilt on every change, so the serial only needs to increase
+	if serial := uint32(time.Now().Unix()); serial > p.serial {
+		p.serial = serial
+	} else {
+		p.serial++
+	}
 	var merr error
 
+	var origins []string
+	for _, v := range p.dnsZones {
+		zone := plugin.Name(v.Spec.Origin).Normalize()
+		origins = append(origins, zone)
+		p.zones.Z[zone] = p.declaredZone(zone, v.Spec)
+		p.zones.Names = append(p.zones.Names, zone)
+	}
+
 	for _, v := range p.records {
 		parts := dns.SplitDomainName(v.Header().Name)
 		if len(parts) < 2 {
 			merr = multierr.Append(merr, fmt.Errorf("malformed name: %s", v.Header().Name))
 			continue
 		}
-		d, err := publicsuffix.Domain(strings.Join(parts, "."))
-		if err != nil {
-			log.Error(err, "parse domain", "domain", v.Header().Name)
-			continue
+		var zone string
+		if len(origins) != 0 {
+			var ok bool
+			if zone, ok = dnszone.Match(v.Header().Name, origins); !ok {
+				merr = multierr.Append(merr, fmt.Errorf("%s is outside of any declared zone", v.Header().Name))
+				continue
+			}
+		} else {
+			// reverse names cannot be resolved with the public suffix list
+			var err error
+			if zone, err = dnszone.For(v.Header().Name); err != nil {
+				log.Error(err, "parse domain", "domain", v.Header().Name)
+				continue
+			}
 		}
-		zone := dns.Fqdn(d)
 		zone = plugin.Name(zone).Normalize()
 		z, ok := p.zones.Z[zone]
 		if !ok {
@@ -145,7 +179,7 @@ func (p *provider) sync() error {
 				Hdr:     dns.RR_Header{Name: k, Rrtype: dns.TypeSOA, Ttl: p.ttl, Class: dns.ClassINET},
 				Mbox:    dnsutil.Join(p.hostmaster, p.apex, k),
 				Ns:      ns,
-				Serial:  1499347823, // Also dynamic?
+				Serial:  p.serial,
 				Refresh: 7200,
 				Retry:   1800,
 				Expire:  86400,
@@ -157,69 +191,99 @@ func (p *provider) sync() error {
 		}
 		header := dns.RR_Header{Name: k, Rrtype: dns.TypeNS, Ttl: p.ttl, Class: dns.ClassINET}
 		v.NS = append(v.NS, &dns.NS{Hdr: header, Ns: ns})
-		if p.externalAddress == nil {
-			continue
-		}
-		nsRecord := &dns.A{
-			Hdr: dns.RR_Header{
-				Name:   ns,
-				Rrtype: dns.TypeA,
-				Class:  dns.ClassINET,
-				Ttl:    p.ttl,
-			},
-			A: p.externalAddress,
-		}
-		if err := v.Insert(nsRecord); err != nil {
-			return err
+		for _, ip := range p.externalAddresses {
+			if err := v.Insert(glue(ns, ip, p.ttl)); err != nil {
+				return err
+			}
 		}
-
 	}
 	return merr
 }
 
+// glue returns the name server's A or AAAA record depending on the address family
+func glue(ns string, ip net.IP, ttl uint32) dns.RR {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &dns.A{Hdr: dns.RR_Header{Name: ns, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}, A: ip4}
+	}
+	return &dns.AAAA{Hdr: dns.RR_Header{Name: ns, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl}, AAAA: ip}
+}
+
+// declaredZone returns an empty zone with the DNSZone's SOA and NS records,
+// the default name server is added by sync when no name servers are declared
+func (p *provider) declaredZone(zone string, spec v1alpha1.DNSZoneSpec) *file.Zone {
+	z := file.NewZone(zone, "")
+	ns := dnsutil.Join("ns0", p.apex, zone)
+	if len(spec.Nameservers) != 0 {
+		ns = spec.Nameservers[0]
+	}
+	z.SOA = &dns.SOA{
+		Hdr:     dns.RR_Header{Name: zone, Rrtype: dns.TypeSOA, Ttl: spec.TTL, Class: dns.ClassINET},
+		Mbox:    spec.SOA.Mbox,
+		Ns:      ns,
+		Serial:  p.serial,
+		Refresh: spec.SOA.Refresh,
+		Retry:   spec.SOA.Retry,
+		Expire:  spec.SOA.Expire,
+		Minttl:  spec.SOA.Minimum,
+	}
+	for _, v := range spec.Nameservers {
+		z.NS = append(z.NS, &dns.NS{Hdr: dns.RR_Header{Name: zone, Rrtype: dns.TypeNS, Ttl: spec.TTL, Class: dns.ClassINET}, Ns: v})
+	}
+	return z
+}
+
 func (p *provider) Run() error {
-	i, err := p.cache.GetInformer(p.ctx, &v1alpha1.DNSRecord{})
+	zi, err := p.cache.GetInformer(p.ctx, &v1alpha1.DNSZone{})
 	if err != nil {
 		return err
 	}
-	i.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+	zi.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			rr, r, err := makeRecord(obj)
-			if err != nil {
-				log.Error(err, "add func handler failed")
-				return
+			p.setZone(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			p.setZone(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if d, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = d.Obj
 			}
-			if !ptr.ToBoolD(r.Spec.Active, true) {
-				log.Info("skip adding inactive record", "record", rr.String())
+			z, ok := obj.(*v1alpha1.DNSZone)
+			if !ok {
 				return
 			}
-			log.Info("adding record", "record", rr.String())
+			log.Info("deleting zone", "zone", z.Spec.Origin)
 			p.mu.Lock()
-			p.records[rr.String()] = rr
+			delete(p.dnsZones, z.Name)
 			p.mu.Unlock()
 			if err := p.sync(); err != nil {
 				log.Error(err, "zones sync had errors")
 			}
 		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			oldRR, r, err := makeRecord(oldObj)
+	})
+	h := toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			rrs, r, err := makeRecord(obj)
 			if err != nil {
-				log.Error(err, "update func handler failed")
+				log.Error(err, "add func handler failed")
 				return
 			}
-			newRR, r, err := makeRecord(newObj)
-			if err != nil {
-				log.Error(err, "update func handler failed")
-				return
+			p.mu.Lock()
+			p.add(ownerKey(obj), r, rrs)
+			p.mu.Unlock()
+			if err := p.sync(); err != nil {
+				log.Error(err, "zones sync had errors")
 			}
-			log.Info("deleting record", "old", oldRR.String())
+		},
+		UpdateFunc: func(_, newObj interface{}) {
 			p.mu.Lock()
-			delete(p.records, oldRR.String())
-			if ptr.ToBoolD(r.Spec.Active, true) {
-				log.Info("adding record", "new", newRR.String())
-				p.records[newRR.String()] = newRR
+			// the previous records are released even if the new object is invalid
+			p.remove(ownerKey(newObj))
+			rrs, r, err := makeRecord(newObj)
+			if err != nil {
+				log.Error(err, "update func handler failed")
 			} else {
-				log.Info("skip adding inactive record", "record", newRR.String())
+				p.add(ownerKey(newObj), r, rrs)
 			}
 			p.mu.Unlock()
 			if err := p.sync(); err != nil {
@@ -227,33 +291,113 @@ func (p *provider) Run() error {
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
-			rr, _, err := makeRecord(obj)
-			if err != nil {
-				log.Error(err, "delete func handler failed")
-				return
+			if d, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = d.Obj
 			}
-			log.Info("deleting record", "record", rr.String())
 			p.mu.Lock()
-			delete(p.records, rr.String())
+			p.remove(ownerKey(obj))
 			p.mu.Unlock()
 			if err := p.sync(); err != nil {
 				log.Error(err, "zones sync had errors")
 			}
 		},
-	})
+	}
+	// namespaced and cluster records are served the same way
+	for _, o := range []client.Object{&v1alpha1.DNSRecord{}, &v1alpha1.ClusterDNSRecord{}} {
+		i, err := p.cache.GetInformer(p.ctx, o)
+		if err != nil {
+			return err
+		}
+		i.AddEventHandler(h)
+	}
 	return p.cache.Start(p.ctx)
 }
 
-func makeRecord(obj interface{}) (dns.RR, *v1alpha1.DNSRecord, error) {
-	r, ok := obj.(*v1alpha1.DNSRecord)
-	if !ok || r == nil {
-		return nil, nil, errors.New("obj is nil or is not a DNSRecord")
+func (p *provider) setZone(obj interface{}) {
+	z, ok := obj.(*v1alpha1.DNSZone)
+	if !ok || z == nil {
+		return
+	}
+	z = z.DeepCopy()
+	z.Default()
+	log.Info("setting zone", "zone", z.Spec.Origin)
+	p.mu.Lock()
+	p.dnsZones[z.Name] = z
+	p.mu.Unlock()
+	if err := p.sync(); err != nil {
+		log.Error(err, "zones sync had errors")
+	}
+}
+
+// add serves the owner's records, it must be called with the lock held
+func (p *provider) add(owner string, r *v1alpha1.DNSRecord, rrs []dns.RR) {
+	for _, rr := range rrs {
+		if !served(r) {
+			log.Info("skip adding inactive or forbidden record", "record", rr.String())
+			continue
+		}
+		log.Info("adding record", "record", rr.String(), "owner", owner)
+		k := rr.String()
+		p.records[k] = rr
+		if p.owners[k] == nil {
+			p.owners[k] = make(map[string]struct{})
+		}
+		p.owners[k][owner] = struct{}{}
+	}
+}
+
+// remove stops serving the owner's records, unless another owner defines them,
+// it must be called with the lock held
+func (p *provider) remove(owner string) {
+	for k, owners := range p.owners {
+		if _, ok := owners[owner]; !ok {
+			continue
+		}
+		delete(owners, owner)
+		if len(owners) != 0 {
+			log.Info("keeping record defined by another owner", "record", k, "owner", owner)
+			continue
+		}
+		log.Info("deleting record", "record", k, "owner", owner)
+		delete(p.owners, k)
+		delete(p.records, k)
+	}
+}
+
+// ownerKey returns the key identifying the DNSRecord or ClusterDNSRecord defining records
+func ownerKey(obj interface{}) string {
+	switch o := obj.(type) {
+	case *v1alpha1.DNSRecord:
+		return fmt.Sprintf("DNSRecord %s/%s", o.Namespace, o.Name)
+	case *v1alpha1.ClusterDNSRecord:
+		return fmt.Sprintf("ClusterDNSRecord %s", o.Name)
+	}
+	return ""
+}
+
+// served returns true if the record is active and not forbidden by a DNSDomainPolicy
+func served(r *v1alpha1.DNSRecord) bool {
+	return ptr.ToBoolD(r.Spec.Active, true) && !meta.IsStatusConditionTrue(r.Status.Conditions, v1alpha1.ConditionForbidden)
+}
+
+func makeRecord(obj interface{}) ([]dns.RR, *v1alpha1.DNSRecord, error) {
+	var r *v1alpha1.DNSRecord
+	switch o := obj.(type) {
+	case *v1alpha1.DNSRecord:
+		r = o
+	case *v1alpha1.ClusterDNSRecord:
+		if o != nil {
+			r = o.DNSRecord()
+		}
+	}
+	if r == nil {
+		return nil, nil, errors.New("obj is nil or is not a DNSRecord nor a ClusterDNSRecord")
 	}
-	rr, err := record.ToRR(*r)
+	rrs, err := record.ToRR(*r)
 	if err != nil {
 		return nil, nil, fmt.Errorf("record conversion: %w", err)
 	}
-	return rr, r, nil
+	return rrs, r, nil
 }
 
 func init() {
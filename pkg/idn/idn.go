@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package idn converts internationalized domain names between their Unicode (U-labels)
+// and ASCII (A-labels, punycode) forms
+package idn
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// profile does not enforce the host name rules, so that wildcards and service labels, e.g. _dmarc, are allowed
+var profile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// ToASCII returns the name's ASCII form, the name is only lower-cased if it cannot be converted
+func ToASCII(name string) string {
+	name = unescape(name)
+	if isASCII(name) {
+		return strings.ToLower(name)
+	}
+	v, err := profile.ToASCII(name)
+	if err != nil {
+		return strings.ToLower(name)
+	}
+	return v
+}
+
+// ToUnicode returns the name's Unicode form, it returns the name as is if it cannot be converted
+func ToUnicode(name string) string {
+	if !strings.Contains(name, "xn--") {
+		return name
+	}
+	v, err := profile.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return v
+}
+
+// IsIDN returns whether the name contains internationalized labels
+func IsIDN(name string) bool {
+	return ToUnicode(ToASCII(name)) != ToASCII(name)
+}
+
+func isASCII(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if v[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// unescape decodes the non ASCII bytes escaped as \DDD in the presentation format, e.g. m\195\188nchen.de.
+func unescape(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+3 < len(v) {
+			if n, err := strconv.Atoi(v[i+1 : i+4]); err == nil && n >= utf8.RuneSelf && n <= 255 {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(v[i])
+	}
+	if !utf8.ValidString(b.String()) {
+		return v
+	}
+	return b.String()
+}
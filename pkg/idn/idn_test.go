@@ -0,0 +1,30 @@
+package idn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDN(t *testing.T) {
+	tests := []struct {
+		name    string
+		ascii   string
+		unicode string
+	}{
+		{name: "www.example.org.", ascii: "www.example.org.", unicode: "www.example.org."},
+		{name: "WWW.Example.ORG.", ascii: "www.example.org.", unicode: "www.example.org."},
+		{name: "München.de.", ascii: "xn--mnchen-3ya.de.", unicode: "münchen.de."},
+		{name: "xn--mnchen-3ya.de.", ascii: "xn--mnchen-3ya.de.", unicode: "münchen.de."},
+		{name: `m\195\188nchen.de.`, ascii: "xn--mnchen-3ya.de.", unicode: "münchen.de."},
+		{name: "*.bücher.de.", ascii: "*.xn--bcher-kva.de.", unicode: "*.bücher.de."},
+		{name: "_dmarc.bücher.de.", ascii: "_dmarc.xn--bcher-kva.de.", unicode: "_dmarc.bücher.de."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.ascii, ToASCII(tt.name))
+			assert.Equal(t, tt.unicode, ToUnicode(ToASCII(tt.name)))
+			assert.Equal(t, tt.ascii != tt.unicode, IsIDN(tt.name))
+		})
+	}
+}
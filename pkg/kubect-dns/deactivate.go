@@ -19,6 +19,7 @@ package kubect_dns
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/miekg/dns"
 	"github.com/spf13/cobra"
@@ -71,8 +72,10 @@ func get(name string) (*v1alpha1.DNSRecord, error) {
 	}
 	rrs := rr.String()
 	for _, v := range records.Items {
-		if v.Status.Record == rrs {
-			return &v, nil
+		for _, vv := range strings.Split(v.Status.Record, "\n") {
+			if vv == rrs {
+				return &v, nil
+			}
 		}
 	}
 	return nil, fmt.Errorf("'%s': record not found", name)
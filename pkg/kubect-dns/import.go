@@ -35,7 +35,8 @@ var (
 		Use:   "import [file]",
 		Short: "import dns bind file zone and print the DNSRecordList to stdout",
 		Example: `
-	kubectl dns import example.org | kubectl apply -f -`,
+	kubectl dns import example.org | kubectl apply -f -
+	kubectl dns import --origin example.org db.example | kubectl apply -f -`,
 		Aliases:      []string{"convert"},
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
@@ -47,7 +48,7 @@ var (
 			if i.IsDir() {
 				return errors.New("input: expected a file, not a directory")
 			}
-			rrs, err := parse(args[0])
+			rrs, err := parse(args[0], defaultOrigin())
 			if err != nil {
 				return err
 			}
@@ -64,10 +65,11 @@ var (
 )
 
 func init() {
+	ImportCmd.Flags().StringVar(&origin, "origin", "", "origin of the relative names, defaults to the namespace's "+v1alpha1.DefaultOriginAnnotation+" annotation")
 	RootCmd.AddCommand(ImportCmd)
 }
 
-func parse(file string) (*v1alpha1.DNSRecordList, error) {
+func parse(file, origin string) (*v1alpha1.DNSRecordList, error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
@@ -80,13 +82,20 @@ func parse(file string) (*v1alpha1.DNSRecordList, error) {
 			Kind:       record.DNSRecordList,
 		},
 	}
-	zp := dns.NewZoneParser(f, "", "")
+	var rrs []dns.RR
+	// the file's $ORIGIN directives take precedence over the default origin
+	zp := dns.NewZoneParser(f, origin, file)
 	for r, ok := zp.Next(); ok; r, ok = zp.Next() {
 		if r == nil {
 			continue
 		}
 		logrus.Info(r)
-		rec := record.FromRR(r)
+		rrs = append(rrs, r)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+	for _, rec := range record.FromRRs(rrs) {
 		rec.Namespace = ns
 		records.Items = append(records.Items, rec)
 	}
@@ -33,37 +33,55 @@ import (
 
 var (
 	quiet   = false
+	cluster = false
 	ListCmd = &cobra.Command{
 		Use:          "list",
 		Short:        "list DNSRecords",
 		Aliases:      []string{"ls", "l"},
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var l v1alpha1.DNSRecordList
-			var opts []client2.ListOption
-			if a, _ := cmd.Flags().GetBool("all-namespaces"); !a {
-				opts = append(opts, client2.InNamespace(ns))
-			}
-			if err := client.List(context.Background(), &l, opts...); err != nil {
+			items, err := listRecords(cmd)
+			if err != nil {
 				return err
 			}
-			if len(l.Items) == 0 {
-				fmt.Printf("No resources found in %s namespace.\n", ns)
+			if len(items) == 0 {
+				if cluster {
+					fmt.Println("No resources found.")
+				} else {
+					fmt.Printf("No resources found in %s namespace.\n", ns)
+				}
 				return nil
 			}
 			if quiet {
-				for _, v := range l.Items {
-					fmt.Printf("%s/%s\n", v.Namespace, v.Name)
+				for _, v := range items {
+					if cluster {
+						fmt.Println(v.Name)
+					} else {
+						fmt.Printf("%s/%s\n", v.Namespace, v.Name)
+					}
 				}
 				return nil
 			}
-			output := []string{
-				"NAME  | NAMESPACE | ACTIVE | RECORD | TTL | | TYPE | VALUE",
+			header := "NAME  | NAMESPACE | ACTIVE | RECORD | TTL | | TYPE | VALUE"
+			if cluster {
+				header = "NAME  | ACTIVE | RECORD | TTL | | TYPE | VALUE"
 			}
-			for _, v := range l.Items {
-				parts := strings.Split(v.Status.Record, "\t")
-				parts = append(append([]string{v.Name, ns, strconv.FormatBool(ptr.ToBool(v.Status.Active))}, parts...))
-				output = append(output, strings.Join(parts, " | "))
+			output := []string{header}
+			for _, v := range items {
+				// multi-values records have one line per value
+				for _, rr := range strings.Split(v.Status.Record, "\n") {
+					parts := strings.Split(rr, "\t")
+					// internationalized names are displayed in both their ASCII and Unicode forms
+					if v.Status.DisplayName != "" && len(parts) != 0 {
+						parts[0] = fmt.Sprintf("%s (%s)", parts[0], v.Status.DisplayName)
+					}
+					cols := []string{v.Name, ns}
+					if cluster {
+						cols = cols[:1]
+					}
+					parts = append(append(cols, strconv.FormatBool(ptr.ToBool(v.Status.Active))), parts...)
+					output = append(output, strings.Join(parts, " | "))
+				}
 			}
 			result := columnize.SimpleFormat(output)
 			fmt.Println(result)
@@ -72,8 +90,33 @@ var (
 	}
 )
 
+// listRecords returns the DNSRecords, or the ClusterDNSRecords as DNSRecords when the cluster flag is set
+func listRecords(cmd *cobra.Command) ([]v1alpha1.DNSRecord, error) {
+	if cluster {
+		var l v1alpha1.ClusterDNSRecordList
+		if err := client.List(context.Background(), &l); err != nil {
+			return nil, err
+		}
+		var items []v1alpha1.DNSRecord
+		for _, v := range l.Items {
+			items = append(items, *v.DNSRecord())
+		}
+		return items, nil
+	}
+	var l v1alpha1.DNSRecordList
+	var opts []client2.ListOption
+	if a, _ := cmd.Flags().GetBool("all-namespaces"); !a {
+		opts = append(opts, client2.InNamespace(ns))
+	}
+	if err := client.List(context.Background(), &l, opts...); err != nil {
+		return nil, err
+	}
+	return l.Items, nil
+}
+
 func init() {
 	RootCmd.AddCommand(ListCmd)
 	ListCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "display only names")
+	ListCmd.Flags().BoolVar(&cluster, "cluster", false, "list ClusterDNSRecords instead of namespaced DNSRecords")
 	genericclioptions.NewResourceBuilderFlags().AddFlags(ListCmd.Flags())
 }
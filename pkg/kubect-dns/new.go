@@ -17,28 +17,37 @@ limitations under the License.
 package kubect_dns
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/miekg/dns"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
 	"go.linka.cloud/k8s/dns/pkg/record"
 )
 
 var (
+	origin string
+
 	NewCmd = &cobra.Command{
 		Use:   "create [record]",
 		Short: "create a DNSRecord from bind record format and print it to stdout",
 		Example: `
-	kubectl dns create 'dns.google.com. IN A 8.8.8.8' | kubectl apply -f -`,
+	kubectl dns create 'dns.google.com. IN A 8.8.8.8' | kubectl apply -f -
+	kubectl dns create --origin example.org 'www IN CNAME @' | kubectl apply -f -`,
 		Aliases:      []string{"new", "add"},
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			rr, err := dns.NewRR(args[0])
-			if err != nil {
+			zp := dns.NewZoneParser(strings.NewReader(args[0]), defaultOrigin(), "")
+			rr, _ := zp.Next()
+			if err := zp.Err(); err != nil {
 				return fmt.Errorf("invalid record: '%s': %v", args[0], err)
 			}
 			if rr == nil {
@@ -57,5 +66,21 @@ var (
 )
 
 func init() {
+	NewCmd.Flags().StringVar(&origin, "origin", "", "origin of the relative names, defaults to the namespace's "+v1alpha1.DefaultOriginAnnotation+" annotation")
 	RootCmd.AddCommand(NewCmd)
 }
+
+// defaultOrigin returns the --origin flag value, or the namespace's default origin if it can be read
+func defaultOrigin() string {
+	if origin != "" {
+		return dns.Fqdn(origin)
+	}
+	var n corev1.Namespace
+	if err := client.Get(context.Background(), client2.ObjectKey{Name: ns}, &n); err != nil {
+		return ""
+	}
+	if o := n.Annotations[v1alpha1.DefaultOriginAnnotation]; o != "" {
+		return dns.Fqdn(o)
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubect_dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ryanuber/columnize"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+var (
+	QuotaCmd = &cobra.Command{
+		Use:          "quota",
+		Short:        "show the namespace's DNSRecords quota and usage",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var n corev1.Namespace
+			if err := client.Get(context.Background(), client2.ObjectKey{Name: ns}, &n); err != nil {
+				return err
+			}
+			q, err := v1alpha1.QuotaFor(&n)
+			if err != nil {
+				return err
+			}
+			if q == nil {
+				q = &v1alpha1.Quota{}
+			}
+			var l v1alpha1.DNSRecordList
+			if err := client.List(context.Background(), &l, client2.InNamespace(ns)); err != nil {
+				return err
+			}
+			total, types := v1alpha1.Usage(l.Items)
+			output := []string{
+				"RESOURCE | USED | LIMIT",
+				strings.Join([]string{"records", strconv.Itoa(total), limit(q.Records, q.Records != 0)}, " | "),
+			}
+			var names []string
+			for k := range types {
+				names = append(names, k)
+			}
+			for k := range q.Types {
+				if _, ok := types[k]; !ok {
+					names = append(names, k)
+				}
+			}
+			sort.Strings(names)
+			for _, v := range names {
+				n, ok := q.Types[v]
+				output = append(output, strings.Join([]string{v, strconv.Itoa(types[v]), limit(n, ok)}, " | "))
+			}
+			if q.MinTTL != 0 {
+				output = append(output, strings.Join([]string{"min ttl", "-", strconv.Itoa(int(q.MinTTL))}, " | "))
+			}
+			fmt.Println(columnize.SimpleFormat(output))
+			return nil
+		},
+	}
+)
+
+func limit(n int, ok bool) string {
+	if !ok {
+		return "none"
+	}
+	return strconv.Itoa(n)
+}
+
+func init() {
+	RootCmd.AddCommand(QuotaCmd)
+}
@@ -19,6 +19,7 @@ package coredns
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	dnsv1alpha1 "go.linka.cloud/k8s/dns/api/v1alpha1"
@@ -28,6 +29,8 @@ import (
 func init() {
 	provider.Register("coredns", func() (provider.Provider, error) {
 		return provider.Func(func(ctx context.Context, rec *dnsv1alpha1.DNSRecord) (ctrl.Result, bool, error) {
+			// the records are served from the cluster by the k8s_dns plugin
+			rec.SetCondition(dnsv1alpha1.ConditionProviderSynced, metav1.ConditionTrue, dnsv1alpha1.ReasonSynced, "record served by the k8s_dns plugin")
 			return ctrl.Result{}, true, nil
 		}), nil
 	})
@@ -26,7 +26,13 @@ import (
 	"go.linka.cloud/k8s/dns/pkg/provider/libdns"
 )
 
-const TokenEnv = "CLOUDFLARE_TOKEN"
+const (
+	TokenEnv = "CLOUDFLARE_TOKEN"
+
+	// MinTTL and MaxTTL are the TTL bounds accepted by the Cloudflare API
+	MinTTL = 60
+	MaxTTL = 86400
+)
 
 func init() {
 	provider.Register("cloudflare", func() (provider.Provider, error) {
@@ -34,6 +40,6 @@ func init() {
 		if tk == "" {
 			return nil, errors.New("empty CF_TOKEN environment variable")
 		}
-		return libdns.New("cloudflare", &cloudflare.Provider{APIToken: tk}), nil
+		return libdns.New("cloudflare", &cloudflare.Provider{APIToken: tk}, libdns.WithTTLRange(MinTTL, MaxTTL)), nil
 	})
 }
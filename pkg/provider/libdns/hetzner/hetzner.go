@@ -28,6 +28,9 @@ import (
 
 const (
 	TokenEnv = "HETZNER_TOKEN"
+
+	// MinTTL is the lowest TTL accepted by the Hetzner API
+	MinTTL = 60
 )
 
 func init() {
@@ -40,6 +43,6 @@ func init() {
 			AuthAPIToken: t,
 		}
 		// hetzner provider seems to have so issues with concurrent requests
-		return libdns.NewSync("hetzner", p), nil
+		return libdns.NewSync("hetzner", p, libdns.WithTTLRange(MinTTL, 0)), nil
 	})
 }
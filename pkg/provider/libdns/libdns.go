@@ -18,20 +18,26 @@ package libdns
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/libdns/libdns"
 	"github.com/miekg/dns"
-	"github.com/weppos/publicsuffix-go/publicsuffix"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"go.linka.cloud/k8s/dns/api/v1alpha1"
 	"go.linka.cloud/k8s/dns/pkg/provider"
 	"go.linka.cloud/k8s/dns/pkg/record"
+	dnszone "go.linka.cloud/k8s/dns/pkg/zone"
 )
 
+// ErrRecordExists is returned when the record is already defined by a record the provider does not own
+var ErrRecordExists = errors.New("record already exists")
+
 type Client interface {
 	libdns.RecordGetter
 	libdns.RecordAppender
@@ -39,121 +45,204 @@ type Client interface {
 }
 
 type prov struct {
-	name string
-	c    Client
+	name   string
+	c      Client
+	minTTL uint32
+	maxTTL uint32
+}
+
+// Option configures the provider
+type Option func(p *prov)
+
+// WithTTLRange clamps the records TTL to the range accepted by the provider, a zero bound is not enforced
+func WithTTLRange(min, max uint32) Option {
+	return func(p *prov) {
+		p.minTTL = min
+		p.maxTTL = max
+	}
 }
 
-func New(name string, c Client) provider.Provider {
-	return &prov{name: name, c: c}
+func New(name string, c Client, opts ...Option) provider.Provider {
+	p := &prov{name: name, c: c}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
 }
 
-func NewSync(name string, c Client) provider.Provider {
-	return &prov{name: name, c: &syncClient{c: c}}
+func NewSync(name string, c Client, opts ...Option) provider.Provider {
+	return New(name, &syncClient{c: c}, opts...)
 }
 
 func (p prov) Reconcile(ctx context.Context, rec *v1alpha1.DNSRecord) (ctrl.Result, bool, error) {
+	res, ok, err := p.reconcile(ctx, rec)
+	switch {
+	case errors.Is(err, ErrRecordExists):
+		rec.SetCondition(v1alpha1.ConditionConflict, metav1.ConditionTrue, v1alpha1.ReasonRecordExists, err.Error())
+		rec.SetCondition(v1alpha1.ConditionProviderSynced, metav1.ConditionFalse, v1alpha1.ReasonRecordExists, err.Error())
+	case err != nil:
+		rec.SetCondition(v1alpha1.ConditionProviderSynced, metav1.ConditionFalse, v1alpha1.ReasonSyncFailed, err.Error())
+	case ok && rec.DeletionTimestamp.IsZero():
+		rec.SetCondition(v1alpha1.ConditionConflict, metav1.ConditionFalse, v1alpha1.ReasonNoConflict, "no conflicting record found")
+		rec.SetCondition(v1alpha1.ConditionProviderSynced, metav1.ConditionTrue, v1alpha1.ReasonSynced, fmt.Sprintf("record synced with %s", p.name))
+	}
+	return res, ok, err
+}
+
+func (p prov) reconcile(ctx context.Context, rec *v1alpha1.DNSRecord) (ctrl.Result, bool, error) {
 	log := ctrl.LoggerFrom(ctx).WithValues("provider", p.name)
 	// we don't own this record, so we should not reconcile it
 	if rec.Status.Provider != "" && rec.Status.Provider != p.name {
 		log.Info("skipping record, not for this provider", "recordProvider", rec.Status.Provider)
 		return ctrl.Result{}, false, nil
 	}
-	rr, err := record.ToRR(*rec)
-	if err != nil {
-		return ctrl.Result{}, false, err
-	}
-	name := rr.Header().Name
-	parts := dns.SplitDomainName(name)
-	if len(parts) < 2 {
-		return ctrl.Result{}, false, fmt.Errorf("malformed name: %s", rr.Header().Name)
+	// records created before multi-values support only have a single id
+	if rec.Status.ID != "" {
+		if !contains(rec.Status.IDs, rec.Status.ID) {
+			rec.Status.IDs = append(rec.Status.IDs, rec.Status.ID)
+		}
+		rec.Status.ID = ""
 	}
-	d, err := publicsuffix.Domain(strings.Join(parts, "."))
+	rrs, err := record.ToRR(*rec)
 	if err != nil {
-		log.Error(err, "parse domain", "domain", name)
 		return ctrl.Result{}, false, err
 	}
-	zone := dns.Fqdn(d)
+	name := rrs[0].Header().Name
+	// the zone is resolved from the declared DNSZones by the controller
+	zone := rec.Status.Zone
+	if zone == "" {
+		zone, err = dnszone.For(name)
+		if err != nil {
+			log.Error(err, "parse domain", "domain", name)
+			return ctrl.Result{}, false, err
+		}
+	}
 	recs, err := p.c.GetRecords(ctx, zone)
-	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "not found") && rec.Status.ID != "" {
+	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "not found") && len(rec.Status.IDs) != 0 {
 		log.Error(err, "get records", "zone", zone)
 		return ctrl.Result{}, false, err
 	}
-	want := makeRecord(rr, zone, rec.Status.ID)
-	var r *libdns.Record
+	var wants []libdns.Record
+	for _, v := range rrs {
+		if ttl := p.clamp(v.Header().Ttl); ttl != v.Header().Ttl {
+			log.V(1).Info("clamping record ttl", "ttl", v.Header().Ttl, "clamped", ttl)
+			v.Header().Ttl = ttl
+		}
+		wants = append(wants, *makeRecord(v, zone, ""))
+	}
+	var got []libdns.Record
 	for _, v := range recs {
-		if v.ID == rec.Status.ID {
-			r = &v
-			break
+		if contains(rec.Status.IDs, v.ID) {
+			got = append(got, v)
 		}
 	}
 
 	if !rec.DeletionTimestamp.IsZero() || (rec.Spec.Active != nil && !*rec.Spec.Active) {
-		if r == nil {
-			rec.Status.ID = ""
+		if len(got) == 0 {
+			rec.Status.IDs = nil
 			rec.Status.Provider = ""
 			return ctrl.Result{}, true, nil
 		}
-		log.Info("delete record", "record", r.Name, "type", r.Type, "value", r.Value)
-		if _, err := p.c.DeleteRecords(ctx, zone, []libdns.Record{*r}); err != nil {
+		for _, v := range got {
+			log.Info("delete record", "record", v.Name, "type", v.Type, "value", v.Value)
+		}
+		if _, err := p.c.DeleteRecords(ctx, zone, got); err != nil {
 			log.Error(err, "delete records", "zone", zone, "record", rec.Name)
 			return ctrl.Result{}, false, err
 		}
-		rec.Status.ID = ""
+		rec.Status.IDs = nil
 		rec.Status.Provider = ""
 		return ctrl.Result{}, true, nil
 	}
 
-	if r == nil {
-		// check if record already exists
-		for _, v := range recs {
-			if Fqdn(v.Name, zone) == want.Name && v.Type == want.Type && v.Value == want.Value {
-				return ctrl.Result{}, false, fmt.Errorf("record already exists: %s", v.Name)
+	var (
+		ids     []string
+		del     []libdns.Record
+		add     []libdns.Record
+		matched = make([]bool, len(wants))
+	)
+	for _, v := range got {
+		r := v
+		FqdnRec(&r, zone)
+		found := false
+		for i, w := range wants {
+			FqdnRec(&w, zone)
+			if !matched[i] && equal(r, w) {
+				matched[i] = true
+				found = true
+				ids = append(ids, v.ID)
+				break
 			}
 		}
-		log.Info("create record")
-		// AppendRecords implementation should prevent from creating duplicate records or overriding existing ones
-		rs, err := p.c.AppendRecords(ctx, zone, []libdns.Record{*want})
-		if err != nil {
-			log.Error(err, "create record", "zone", zone, "record", want.Name)
-			return ctrl.Result{}, false, err
+		if !found {
+			del = append(del, v)
 		}
-		if len(rs) != 1 {
-			return ctrl.Result{}, false, fmt.Errorf("expected 1 record, got %d", len(rs))
+	}
+	for i, w := range wants {
+		if matched[i] {
+			continue
 		}
-		rec.Status.Provider = p.name
-		rec.Status.ID = rs[0].ID
-		return ctrl.Result{}, true, nil
+		// check if record already exists
+		n := w
+		FqdnRec(&n, zone)
+		for _, v := range recs {
+			if contains(rec.Status.IDs, v.ID) {
+				continue
+			}
+			FqdnRec(&v, zone)
+			if v.Name == n.Name && v.Type == n.Type && v.Value == n.Value {
+				return ctrl.Result{}, false, fmt.Errorf("%w: %s", ErrRecordExists, v.Name)
+			}
+		}
+		add = append(add, w)
 	}
 
-	FqdnRec(r, zone)
-	if r.Name == want.Name &&
-		r.Value == want.Value &&
-		r.Type == want.Type &&
-		r.TTL == want.TTL {
+	if len(del) == 0 && len(add) == 0 {
 		log.Info("record up to date")
+		rec.Status.Provider = p.name
+		rec.Status.IDs = ids
 		return ctrl.Result{}, true, nil
 	}
 
-	log.Info("update record: delete")
-	if _, err := p.c.DeleteRecords(ctx, zone, []libdns.Record{*r}); err != nil {
-		log.Error(err, "delete record", "zone", zone, "record", r.Name)
-		return ctrl.Result{}, false, err
-	}
-	log.Info("update record: create")
-	rs, err := p.c.AppendRecords(ctx, zone, []libdns.Record{*want})
-	if err != nil {
-		log.Error(err, "append record", "zone", zone, "record", want.Name)
-		return ctrl.Result{}, false, err
+	if len(del) != 0 {
+		log.Info("update record: delete", "count", len(del))
+		if _, err := p.c.DeleteRecords(ctx, zone, del); err != nil {
+			log.Error(err, "delete records", "zone", zone, "record", rec.Name)
+			return ctrl.Result{}, false, err
+		}
 	}
-	if len(rs) != 1 {
-		return ctrl.Result{}, false, fmt.Errorf("expected 1 record, got %d", len(rs))
+	if len(add) != 0 {
+		log.Info("create record", "count", len(add))
+		// AppendRecords implementation should prevent from creating duplicate records or overriding existing ones
+		rs, err := p.c.AppendRecords(ctx, zone, add)
+		if err != nil {
+			log.Error(err, "create records", "zone", zone, "record", rec.Name)
+			return ctrl.Result{}, false, err
+		}
+		if len(rs) != len(add) {
+			return ctrl.Result{}, false, fmt.Errorf("expected %d records, got %d", len(add), len(rs))
+		}
+		for _, v := range rs {
+			ids = append(ids, v.ID)
+		}
 	}
 
 	rec.Status.Provider = p.name
-	rec.Status.ID = rs[0].ID
+	rec.Status.IDs = ids
 	return ctrl.Result{}, true, nil
 }
 
+// clamp returns the ttl within the provider's accepted range
+func (p prov) clamp(ttl uint32) uint32 {
+	if p.minTTL != 0 && ttl < p.minTTL {
+		return p.minTTL
+	}
+	if p.maxTTL != 0 && ttl > p.maxTTL {
+		return p.maxTTL
+	}
+	return ttl
+}
+
 func makeRecord(rr dns.RR, zone string, id string) *libdns.Record {
 	rec := &libdns.Record{
 		ID:   id,
@@ -172,10 +261,18 @@ func makeRecord(rr dns.RR, zone string, id string) *libdns.Record {
 		rec.Value = fmt.Sprintf("%d %s", r.Preference, r.Mx)
 	case *dns.NS:
 		rec.Value = r.Ns
+	case *dns.PTR:
+		rec.Value = r.Ptr
 	case *dns.SRV:
 		rec.Value = fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target)
 	case *dns.TXT:
-		rec.Value = strings.Join(r.Txt, "")
+		rec.Value = txtValue(r.Txt)
+	case *dns.CAA:
+		rec.Value = caaValue(strconv.Itoa(int(r.Flag)), r.Tag, r.Value)
+	case *dns.SVCB, *dns.HTTPS:
+		rec.Value = rdata(r)
+	case *dns.TLSA:
+		rec.Value = fmt.Sprintf("%d %d %d %s", r.Usage, r.Selector, r.MatchingType, r.Certificate)
 	}
 	return rec
 }
@@ -183,11 +280,123 @@ func makeRecord(rr dns.RR, zone string, id string) *libdns.Record {
 func FqdnRec(rec *libdns.Record, zone string) {
 	rec.Name = Fqdn(rec.Name, zone)
 	switch rec.Type {
-	case "A", "AAAA", "TXT":
-		rec.Value = Unquote(strings.Replace(rec.Value, "\" \"", "", -1))
-	case "CNAME", "MX", "NS", "SRV":
+	case "A", "AAAA":
+		rec.Value = Unquote(rec.Value)
+	case "TXT":
+		// providers return the strings either quoted or joined, only the content is compared
+		rec.Value = strings.Join(txtStrings(rec.Value), "")
+	case "CNAME", "MX", "NS", "PTR", "SRV":
 		rec.Value = Unquote(Fqdn(rec.Value, zone))
+	case "CAA":
+		// providers may return the value with or without quotes
+		if parts := strings.Fields(rec.Value); len(parts) >= 3 {
+			rec.Value = caaValue(parts[0], strings.ToLower(parts[1]), Unquote(strings.Join(parts[2:], " ")))
+		}
+	case "TLSA":
+		// providers may return the certificate association data in upper case
+		rec.Value = strings.ToLower(strings.Join(strings.Fields(rec.Value), " "))
+	case "HTTPS", "SVCB":
+		// parse and print the value back so that the target and the params use the same encoding as makeRecord
+		parts := strings.Fields(rec.Value)
+		if len(parts) < 2 {
+			return
+		}
+		if parts[1] != "." {
+			parts[1] = Fqdn(parts[1], zone)
+		}
+		zp := dns.NewZoneParser(strings.NewReader(fmt.Sprintf("%s %d %s %s", rec.Name, int(rec.TTL.Seconds()), rec.Type, strings.Join(parts, " "))), ".", "")
+		if rr, ok := zp.Next(); ok && zp.Err() == nil {
+			rec.Value = rdata(rr)
+		}
+	}
+}
+
+// txtValue encodes the TXT record's strings, multiple strings are quoted to keep their boundaries
+func txtValue(txt []string) string {
+	if len(txt) == 1 {
+		return txt[0]
+	}
+	var parts []string
+	for _, v := range txt {
+		parts = append(parts, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v)+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// txtStrings decodes a TXT value returned by a provider, either a single unquoted string
+// or a list of quoted strings, e.g. "v=DKIM1; k=rsa; p=MIIB..." "...IDAQAB"
+func txtStrings(v string) []string {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, `"`) {
+		return []string{v}
+	}
+	var (
+		out    []string
+		cur    strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case !quoted && c == '"':
+			quoted = true
+		case !quoted && (c == ' ' || c == '\t'):
+		case !quoted:
+			// not a list of quoted strings
+			return []string{Unquote(v)}
+		case c == '"':
+			quoted = false
+			out = append(out, cur.String())
+			cur.Reset()
+		case c == '\\' && i+3 < len(v) && isDigits(v[i+1:i+4]):
+			n, _ := strconv.Atoi(v[i+1 : i+4])
+			cur.WriteByte(byte(n))
+			i += 3
+		case c == '\\' && i+1 < len(v):
+			i++
+			cur.WriteByte(v[i])
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if quoted {
+		return []string{Unquote(v)}
+	}
+	return out
+}
+
+func isDigits(v string) bool {
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// rdata returns the record's presentation format without the header
+func rdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+func caaValue(flag, tag, value string) string {
+	return fmt.Sprintf("%s %s \"%s\"", flag, tag, value)
+}
+
+func equal(a, b libdns.Record) bool {
+	return a.Name == b.Name &&
+		a.Value == b.Value &&
+		a.Type == b.Type &&
+		a.TTL == b.TTL
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
 	}
+	return false
 }
 
 func Fqdn(name string, zone string) string {
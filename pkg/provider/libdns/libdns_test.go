@@ -0,0 +1,68 @@
+package libdns
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTXTNormalization(t *testing.T) {
+	key := strings.Repeat("A", 300)
+	rr := &dns.TXT{
+		Hdr: dns.RR_Header{Name: "mail._domainkey.example.org.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 3600},
+		Txt: []string{"v=DKIM1; k=rsa; p=" + key[:237], key[237:]},
+	}
+	want := makeRecord(rr, "example.org.", "")
+	assert.Equal(t, `"v=DKIM1; k=rsa; p=`+key[:237]+`" "`+key[237:]+`"`, want.Value)
+	FqdnRec(want, "example.org.")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "quoted strings", value: `"v=DKIM1; k=rsa; p=` + key[:237] + `" "` + key[237:] + `"`},
+		{name: "joined", value: "v=DKIM1; k=rsa; p=" + key},
+		{name: "quoted joined", value: `"v=DKIM1; k=rsa; p=` + key + `"`},
+		{name: "split elsewhere", value: `"v=DKIM1; k=rsa; p=` + key[:10] + `"  "` + key[10:] + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := libdns.Record{Type: "TXT", Name: "mail._domainkey", Value: tt.value, TTL: time.Hour}
+			FqdnRec(&got, "example.org.")
+			assert.True(t, equal(got, *want), "%s != %s", got.Value, want.Value)
+		})
+	}
+}
+
+func TestTXTStrings(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "v=spf1 mx -all", want: []string{"v=spf1 mx -all"}},
+		{value: `"v=spf1 mx -all"`, want: []string{"v=spf1 mx -all"}},
+		{value: `"a" "b"`, want: []string{"a", "b"}},
+		{value: `"say \"hi\"" "back\\slash"`, want: []string{`say "hi"`, `back\slash`}},
+		{value: `"semi\059colon"`, want: []string{"semi;colon"}},
+		{value: `"unterminated`, want: []string{"unterminated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, txtStrings(tt.value))
+		})
+	}
+	assert.Equal(t, []string{`say "hi"`, `back\slash`}, txtStrings(txtValue([]string{`say "hi"`, `back\slash`})))
+}
+
+func TestClampTTL(t *testing.T) {
+	p := New("test", nil, WithTTLRange(60, 86400)).(*prov)
+	assert.Equal(t, uint32(60), p.clamp(1))
+	assert.Equal(t, uint32(300), p.clamp(300))
+	assert.Equal(t, uint32(86400), p.clamp(604800))
+	p = New("test", nil, WithTTLRange(60, 0)).(*prov)
+	assert.Equal(t, uint32(604800), p.clamp(604800))
+}
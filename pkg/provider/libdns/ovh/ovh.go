@@ -31,6 +31,9 @@ const (
 	AppKeyEnv      = "OVH_APPLICATION_KEY"
 	AppSecretEnv   = "OVH_APPLICATION_SECRET"
 	ConsumerKeyEnv = "OVH_CONSUMER_KEY"
+
+	// MinTTL is the lowest TTL accepted by the OVH API
+	MinTTL = 60
 )
 
 func init() {
@@ -57,6 +60,6 @@ func init() {
 			ApplicationSecret: appSecret,
 			ConsumerKey:       consumerKey,
 		}
-		return libdns.New("ovh", p), nil
+		return libdns.New("ovh", p, libdns.WithTTLRange(MinTTL, 0)), nil
 	})
 }
@@ -29,6 +29,9 @@ import (
 const (
 	SecretKeyEnv      = "SCALEWAY_SECRET_KEY"
 	OrganizationIDEnv = "SCALEWAY_ORGANIZATION_ID"
+
+	// MinTTL is the lowest TTL accepted by the Scaleway API
+	MinTTL = 60
 )
 
 func init() {
@@ -45,6 +48,6 @@ func init() {
 			SecretKey:      k,
 			OrganizationID: o,
 		}
-		return libdns.New("scaleway", p), nil
+		return libdns.New("scaleway", p, libdns.WithTTLRange(MinTTL, 0)), nil
 	})
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+func spfValue(r *v1alpha1.SPFRecord) string {
+	return strings.Join(append([]string{"v=spf1"}, r.Mechanisms...), " ")
+}
+
+func dmarcValue(r *v1alpha1.DMARCRecord) string {
+	parts := []string{"v=DMARC1", "p=" + r.Policy}
+	if r.SubdomainPolicy != "" {
+		parts = append(parts, "sp="+r.SubdomainPolicy)
+	}
+	if r.Percent != nil {
+		parts = append(parts, fmt.Sprintf("pct=%d", *r.Percent))
+	}
+	if len(r.ReportAggregate) != 0 {
+		parts = append(parts, "rua="+strings.Join(r.ReportAggregate, ","))
+	}
+	if len(r.ReportFailure) != 0 {
+		parts = append(parts, "ruf="+strings.Join(r.ReportFailure, ","))
+	}
+	if r.AlignmentDKIM != "" {
+		parts = append(parts, "adkim="+r.AlignmentDKIM)
+	}
+	if r.AlignmentSPF != "" {
+		parts = append(parts, "aspf="+r.AlignmentSPF)
+	}
+	if r.FailureOptions != "" {
+		parts = append(parts, "fo="+r.FailureOptions)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func dkimValue(r *v1alpha1.DKIMRecord) string {
+	parts := []string{"v=DKIM1", "k=" + r.KeyType}
+	if r.Testing {
+		parts = append(parts, "t=y")
+	}
+	parts = append(parts, "p="+r.PublicKey)
+	return strings.Join(parts, "; ")
+}
+
+// chunk splits the value in character-strings of at most 255 bytes once unescaped,
+// without splitting the escape sequences (\X and \DDD) nor the multi-byte characters
+func chunk(v string) []string {
+	var (
+		out   []string
+		start int
+		size  int
+	)
+	for i := 0; i < len(v); {
+		// n is the length of the character in v and l its length on the wire, where an escape sequence is a single byte
+		n, l := 1, 1
+		switch {
+		case v[i] == '\\' && isDDD(v[i+1:]):
+			n = 4
+		case v[i] == '\\' && i+1 < len(v):
+			n = 2
+		default:
+			_, n = utf8.DecodeRuneInString(v[i:])
+			l = n
+		}
+		if size+l > 255 {
+			out = append(out, v[start:i])
+			start, size = i, 0
+		}
+		size += l
+		i += n
+	}
+	return append(out, v[start:])
+}
+
+func isDDD(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package record
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunk(t *testing.T) {
+	a := strings.Repeat("a", 254)
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "short",
+			value: "v=spf1 -all",
+			want:  []string{"v=spf1 -all"},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  []string{""},
+		},
+		{
+			name:  "ascii",
+			value: strings.Repeat("a", 300),
+			want:  []string{strings.Repeat("a", 255), strings.Repeat("a", 45)},
+		},
+		{
+			name:  "multi-byte character at the boundary",
+			value: a + "é" + `\"\255x`,
+			want:  []string{a, `é\"\255x`},
+		},
+		{
+			name:  "escape sequences at the boundary",
+			value: a + `\"\123b`,
+			want:  []string{a + `\"`, `\123b`},
+		},
+		{
+			name:  "escape sequences count as a single byte",
+			value: strings.Repeat(`\"`, 200) + strings.Repeat(`\097`, 100),
+			want:  []string{strings.Repeat(`\"`, 200) + strings.Repeat(`\097`, 55), strings.Repeat(`\097`, 45)},
+		},
+		{
+			name:  "non-ascii",
+			value: strings.Repeat("ü", 200),
+			want:  []string{strings.Repeat("ü", 127), strings.Repeat("ü", 73)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chunk(tt.value)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.value, strings.Join(got, ""))
+			rr := &dns.TXT{Hdr: dns.RR_Header{Name: "example.org.", Rrtype: dns.TypeTXT, Class: dns.ClassINET}, Txt: got}
+			_, err := dns.PackRR(rr, make([]byte, dns.Len(rr)), 0, nil, false)
+			require.NoError(t, err)
+		})
+	}
+}
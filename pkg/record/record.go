@@ -17,6 +17,7 @@ limitations under the License.
 package record
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
@@ -27,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"go.linka.cloud/k8s/dns/api/v1alpha1"
+	"go.linka.cloud/k8s/dns/pkg/idn"
 )
 
 const (
@@ -36,6 +38,9 @@ const (
 )
 
 func FromRR(r dns.RR) v1alpha1.DNSRecord {
+	// internationalized names are stored in their ASCII (punycode) form
+	r = dns.Copy(r)
+	r.Header().Name = idn.ToASCII(r.Header().Name)
 	name := strings.TrimSuffix(r.Header().Name, ".")
 	name = strings.Replace(name, ".", "-", -1)
 	name = strings.Replace(name, "_", "", -1)
@@ -58,10 +63,19 @@ func FromRR(r dns.RR) v1alpha1.DNSRecord {
 	case *dns.A:
 		spec = v1alpha1.DNSRecordSpec{
 			A: &v1alpha1.ARecord{
-				Name:   rr.Hdr.Name,
-				Class:  rr.Hdr.Class,
-				Ttl:    rr.Hdr.Ttl,
-				Target: rr.A.String(),
+				Name:    rr.Hdr.Name,
+				Class:   rr.Hdr.Class,
+				Ttl:     rr.Hdr.Ttl,
+				Targets: []string{rr.A.String()},
+			},
+		}
+	case *dns.AAAA:
+		spec = v1alpha1.DNSRecordSpec{
+			AAAA: &v1alpha1.AAAARecord{
+				Name:    rr.Hdr.Name,
+				Class:   rr.Hdr.Class,
+				Ttl:     rr.Hdr.Ttl,
+				Targets: []string{rr.AAAA.String()},
 			},
 		}
 	case *dns.CNAME:
@@ -70,7 +84,7 @@ func FromRR(r dns.RR) v1alpha1.DNSRecord {
 				Name:   rr.Hdr.Name,
 				Class:  rr.Hdr.Class,
 				Ttl:    rr.Hdr.Ttl,
-				Target: rr.Target,
+				Target: idn.ToASCII(rr.Target),
 			},
 		}
 	case *dns.SRV:
@@ -79,7 +93,7 @@ func FromRR(r dns.RR) v1alpha1.DNSRecord {
 				Name:     rr.Hdr.Name,
 				Class:    rr.Hdr.Class,
 				Ttl:      rr.Hdr.Ttl,
-				Target:   rr.Target,
+				Target:   idn.ToASCII(rr.Target),
 				Priority: rr.Priority,
 				Weight:   rr.Weight,
 				Port:     rr.Port,
@@ -101,7 +115,60 @@ func FromRR(r dns.RR) v1alpha1.DNSRecord {
 				Class:      rr.Hdr.Class,
 				Ttl:        rr.Hdr.Ttl,
 				Preference: rr.Preference,
-				Target:     rr.Mx,
+				Target:     idn.ToASCII(rr.Mx),
+			},
+		}
+	case *dns.NS:
+		spec = v1alpha1.DNSRecordSpec{
+			NS: &v1alpha1.NSRecord{
+				Name:    rr.Hdr.Name,
+				Class:   rr.Hdr.Class,
+				Ttl:     rr.Hdr.Ttl,
+				Targets: []string{idn.ToASCII(rr.Ns)},
+			},
+		}
+	case *dns.PTR:
+		spec = v1alpha1.DNSRecordSpec{
+			PTR: &v1alpha1.PTRRecord{
+				Name:   rr.Hdr.Name,
+				Class:  rr.Hdr.Class,
+				Ttl:    rr.Hdr.Ttl,
+				Target: idn.ToASCII(rr.Ptr),
+			},
+		}
+	case *dns.CAA:
+		spec = v1alpha1.DNSRecordSpec{
+			CAA: &v1alpha1.CAARecord{
+				Name:  rr.Hdr.Name,
+				Class: rr.Hdr.Class,
+				Ttl:   rr.Hdr.Ttl,
+				Flag:  rr.Flag,
+				Tag:   rr.Tag,
+				Value: rr.Value,
+			},
+		}
+	case *dns.SVCB:
+		if svcb, ok := fromSVCB(rr); ok {
+			spec = v1alpha1.DNSRecordSpec{SVCB: svcb}
+		} else {
+			spec = v1alpha1.DNSRecordSpec{Raw: rr.String()}
+		}
+	case *dns.HTTPS:
+		if svcb, ok := fromSVCB(&rr.SVCB); ok {
+			spec = v1alpha1.DNSRecordSpec{HTTPS: svcb}
+		} else {
+			spec = v1alpha1.DNSRecordSpec{Raw: rr.String()}
+		}
+	case *dns.TLSA:
+		spec = v1alpha1.DNSRecordSpec{
+			TLSA: &v1alpha1.TLSARecord{
+				Name:         rr.Hdr.Name,
+				Class:        rr.Hdr.Class,
+				Ttl:          rr.Hdr.Ttl,
+				Usage:        rr.Usage,
+				Selector:     rr.Selector,
+				MatchingType: rr.MatchingType,
+				Certificate:  rr.Certificate,
 			},
 		}
 	default:
@@ -113,7 +180,71 @@ func FromRR(r dns.RR) v1alpha1.DNSRecord {
 	return record
 }
 
-func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
+// fromSVCB converts the SVCB record, it returns false if the record uses parameters not supported by the API
+func fromSVCB(rr *dns.SVCB) (*v1alpha1.SVCBRecord, bool) {
+	r := &v1alpha1.SVCBRecord{
+		Name:     rr.Hdr.Name,
+		Class:    rr.Hdr.Class,
+		Ttl:      rr.Hdr.Ttl,
+		Priority: rr.Priority,
+		Target:   idn.ToASCII(rr.Target),
+	}
+	for _, v := range rr.Value {
+		switch kv := v.(type) {
+		case *dns.SVCBAlpn:
+			r.Alpn = kv.Alpn
+		case *dns.SVCBNoDefaultAlpn:
+			r.NoDefaultAlpn = true
+		case *dns.SVCBPort:
+			r.Port = kv.Port
+		case *dns.SVCBIPv4Hint:
+			for _, ip := range kv.Hint {
+				r.IPv4Hint = append(r.IPv4Hint, ip.String())
+			}
+		case *dns.SVCBIPv6Hint:
+			for _, ip := range kv.Hint {
+				r.IPv6Hint = append(r.IPv6Hint, ip.String())
+			}
+		case *dns.SVCBECHConfig:
+			r.ECH = base64.StdEncoding.EncodeToString(kv.ECH)
+		default:
+			return nil, false
+		}
+	}
+	return r, true
+}
+
+// FromRRs converts the resource records to DNSRecords, merging the A, AAAA and NS RRsets into a single DNSRecord
+func FromRRs(rrs []dns.RR) []v1alpha1.DNSRecord {
+	var out []v1alpha1.DNSRecord
+	index := make(map[string]int)
+	for _, v := range rrs {
+		rec := FromRR(v)
+		if i, ok := index[rec.Name]; ok && merge(&out[i], rec) {
+			continue
+		}
+		index[rec.Name] = len(out)
+		out = append(out, rec)
+	}
+	return out
+}
+
+func merge(dst *v1alpha1.DNSRecord, src v1alpha1.DNSRecord) bool {
+	switch {
+	case dst.Spec.A != nil && src.Spec.A != nil && dst.Spec.A.Name == src.Spec.A.Name:
+		dst.Spec.A.Targets = append(dst.Spec.A.Targets, src.Spec.A.Targets...)
+	case dst.Spec.AAAA != nil && src.Spec.AAAA != nil && dst.Spec.AAAA.Name == src.Spec.AAAA.Name:
+		dst.Spec.AAAA.Targets = append(dst.Spec.AAAA.Targets, src.Spec.AAAA.Targets...)
+	case dst.Spec.NS != nil && src.Spec.NS != nil && dst.Spec.NS.Name == src.Spec.NS.Name:
+		dst.Spec.NS.Targets = append(dst.Spec.NS.Targets, src.Spec.NS.Targets...)
+	default:
+		return false
+	}
+	return true
+}
+
+// ToRR converts the record to its resource records, one per record value
+func ToRR(r v1alpha1.DNSRecord) ([]dns.RR, error) {
 	switch {
 	case r.Spec.A != nil:
 		h := dns.RR_Header{
@@ -122,11 +253,39 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 			Class:  r.Spec.A.Class,
 			Ttl:    r.Spec.A.Ttl,
 		}
-		ip := net.ParseIP(r.Spec.A.Target)
-		if ip == nil {
-			return nil, fmt.Errorf("invalid ip: %s", r.Spec.A.Target)
+		targets := r.Spec.A.AllTargets()
+		if len(targets) == 0 {
+			return nil, errors.New("'targets' is required for A Records")
+		}
+		var rrs []dns.RR
+		for _, v := range targets {
+			ip := net.ParseIP(v)
+			if ip == nil || ip.To4() == nil {
+				return nil, fmt.Errorf("invalid ip: %s", v)
+			}
+			rrs = append(rrs, &dns.A{Hdr: h, A: ip})
+		}
+		return rrs, nil
+	case r.Spec.AAAA != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.AAAA.Name,
+			Rrtype: dns.TypeAAAA,
+			Class:  r.Spec.AAAA.Class,
+			Ttl:    r.Spec.AAAA.Ttl,
+		}
+		targets := r.Spec.AAAA.AllTargets()
+		if len(targets) == 0 {
+			return nil, errors.New("'targets' is required for AAAA Records")
 		}
-		return &dns.A{Hdr: h, A: ip}, nil
+		var rrs []dns.RR
+		for _, v := range targets {
+			ip := net.ParseIP(v)
+			if ip == nil || ip.To4() != nil {
+				return nil, fmt.Errorf("invalid ipv6: %s", v)
+			}
+			rrs = append(rrs, &dns.AAAA{Hdr: h, AAAA: ip})
+		}
+		return rrs, nil
 	case r.Spec.TXT != nil:
 		h := dns.RR_Header{
 			Name:   r.Spec.TXT.Name,
@@ -137,7 +296,12 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 		if len(r.Spec.TXT.Targets) == 0 {
 			return nil, errors.New("empty TXT record")
 		}
-		return &dns.TXT{Hdr: h, Txt: r.Spec.TXT.Targets}, nil
+		// character-strings are limited to 255 bytes, longer targets are split
+		var txt []string
+		for _, v := range r.Spec.TXT.Targets {
+			txt = append(txt, chunk(v)...)
+		}
+		return []dns.RR{&dns.TXT{Hdr: h, Txt: txt}}, nil
 	case r.Spec.SRV != nil:
 		h := dns.RR_Header{
 			Name:   r.Spec.SRV.Name,
@@ -148,13 +312,13 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 		if r.Spec.SRV.Target == "" {
 			return nil, errors.New("'target' is required for SRV Records")
 		}
-		return &dns.SRV{
+		return []dns.RR{&dns.SRV{
 			Hdr:      h,
 			Priority: r.Spec.SRV.Priority,
 			Weight:   r.Spec.SRV.Weight,
 			Port:     r.Spec.SRV.Port,
 			Target:   r.Spec.SRV.Target,
-		}, nil
+		}}, nil
 	case r.Spec.MX != nil:
 		h := dns.RR_Header{
 			Name:   r.Spec.MX.Name,
@@ -162,7 +326,7 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 			Class:  r.Spec.MX.Class,
 			Ttl:    r.Spec.MX.Ttl,
 		}
-		return &dns.MX{Hdr: h, Preference: r.Spec.MX.Preference, Mx: r.Spec.MX.Target}, nil
+		return []dns.RR{&dns.MX{Hdr: h, Preference: r.Spec.MX.Preference, Mx: r.Spec.MX.Target}}, nil
 	case r.Spec.CNAME != nil:
 		h := dns.RR_Header{
 			Name:   r.Spec.CNAME.Name,
@@ -173,7 +337,119 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 		if r.Spec.CNAME.Target == "" {
 			return nil, errors.New("'target' is required for CNAME Records")
 		}
-		return &dns.CNAME{Hdr: h, Target: r.Spec.CNAME.Target}, nil
+		return []dns.RR{&dns.CNAME{Hdr: h, Target: r.Spec.CNAME.Target}}, nil
+	case r.Spec.CAA != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.CAA.Name,
+			Rrtype: dns.TypeCAA,
+			Class:  r.Spec.CAA.Class,
+			Ttl:    r.Spec.CAA.Ttl,
+		}
+		if r.Spec.CAA.Tag == "" {
+			return nil, errors.New("'tag' is required for CAA Records")
+		}
+		return []dns.RR{&dns.CAA{Hdr: h, Flag: r.Spec.CAA.Flag, Tag: r.Spec.CAA.Tag, Value: r.Spec.CAA.Value}}, nil
+	case r.Spec.NS != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.NS.Name,
+			Rrtype: dns.TypeNS,
+			Class:  r.Spec.NS.Class,
+			Ttl:    r.Spec.NS.Ttl,
+		}
+		if len(r.Spec.NS.Targets) == 0 {
+			return nil, errors.New("'targets' is required for NS Records")
+		}
+		var rrs []dns.RR
+		for _, v := range r.Spec.NS.Targets {
+			if v == "" {
+				return nil, errors.New("empty NS record target")
+			}
+			rrs = append(rrs, &dns.NS{Hdr: h, Ns: v})
+		}
+		return rrs, nil
+	case r.Spec.PTR != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.PTR.Name,
+			Rrtype: dns.TypePTR,
+			Class:  r.Spec.PTR.Class,
+			Ttl:    r.Spec.PTR.Ttl,
+		}
+		if r.Spec.PTR.Target == "" {
+			return nil, errors.New("'target' is required for PTR Records")
+		}
+		return []dns.RR{&dns.PTR{Hdr: h, Ptr: r.Spec.PTR.Target}}, nil
+	case r.Spec.SVCB != nil:
+		rr, err := toSVCB(r.Spec.SVCB, dns.TypeSVCB)
+		if err != nil {
+			return nil, err
+		}
+		return []dns.RR{rr}, nil
+	case r.Spec.HTTPS != nil:
+		rr, err := toSVCB(r.Spec.HTTPS, dns.TypeHTTPS)
+		if err != nil {
+			return nil, err
+		}
+		return []dns.RR{&dns.HTTPS{SVCB: *rr}}, nil
+	case r.Spec.TLSA != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.TLSA.Name,
+			Rrtype: dns.TypeTLSA,
+			Class:  r.Spec.TLSA.Class,
+			Ttl:    r.Spec.TLSA.Ttl,
+		}
+		cert := r.Spec.TLSA.Certificate
+		// the certificate association data computed from the Secret is stored in the status
+		if r.Spec.TLSA.SecretName != "" {
+			cert = r.Status.SecretData
+		}
+		if cert == "" {
+			return nil, errors.New("'certificate' is required for TLSA Records")
+		}
+		return []dns.RR{&dns.TLSA{
+			Hdr:          h,
+			Usage:        r.Spec.TLSA.Usage,
+			Selector:     r.Spec.TLSA.Selector,
+			MatchingType: r.Spec.TLSA.MatchingType,
+			Certificate:  cert,
+		}}, nil
+	case r.Spec.SPF != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.SPF.Name,
+			Rrtype: dns.TypeTXT,
+			Class:  r.Spec.SPF.Class,
+			Ttl:    r.Spec.SPF.Ttl,
+		}
+		if len(r.Spec.SPF.Mechanisms) == 0 {
+			return nil, errors.New("'mechanisms' is required for SPF Records")
+		}
+		return []dns.RR{&dns.TXT{Hdr: h, Txt: chunk(spfValue(r.Spec.SPF))}}, nil
+	case r.Spec.DMARC != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.DMARC.Name,
+			Rrtype: dns.TypeTXT,
+			Class:  r.Spec.DMARC.Class,
+			Ttl:    r.Spec.DMARC.Ttl,
+		}
+		if r.Spec.DMARC.Policy == "" {
+			return nil, errors.New("'policy' is required for DMARC Records")
+		}
+		return []dns.RR{&dns.TXT{Hdr: h, Txt: chunk(dmarcValue(r.Spec.DMARC))}}, nil
+	case r.Spec.DKIM != nil:
+		h := dns.RR_Header{
+			Name:   r.Spec.DKIM.Name,
+			Rrtype: dns.TypeTXT,
+			Class:  r.Spec.DKIM.Class,
+			Ttl:    r.Spec.DKIM.Ttl,
+		}
+		dkim := *r.Spec.DKIM
+		// the public key computed from the Secret is stored in the status
+		if dkim.SecretName != "" {
+			dkim.PublicKey = r.Status.SecretData
+		}
+		if dkim.PublicKey == "" {
+			return nil, errors.New("'publicKey' is required for DKIM Records")
+		}
+		return []dns.RR{&dns.TXT{Hdr: h, Txt: chunk(dkimValue(&dkim))}}, nil
 	default:
 		if r.Spec.Raw == "" {
 			return nil, errors.New("unknown record type")
@@ -185,14 +461,72 @@ func ToRR(r v1alpha1.DNSRecord) (dns.RR, error) {
 		if rr == nil {
 			return nil, fmt.Errorf("invalid record: '%s'", r.Spec.Raw)
 		}
-		return rr, nil
+		return []dns.RR{rr}, nil
+	}
+}
+
+func toSVCB(r *v1alpha1.SVCBRecord, typ uint16) (*dns.SVCB, error) {
+	rr := &dns.SVCB{
+		Hdr: dns.RR_Header{
+			Name:   r.Name,
+			Rrtype: typ,
+			Class:  r.Class,
+			Ttl:    r.Ttl,
+		},
+		Priority: r.Priority,
+		Target:   r.Target,
+	}
+	if rr.Target == "" {
+		return nil, fmt.Errorf("'target' is required for %s Records", dns.TypeToString[typ])
+	}
+	// the values are kept in key order as required by the wire format
+	if len(r.Alpn) != 0 {
+		rr.Value = append(rr.Value, &dns.SVCBAlpn{Alpn: r.Alpn})
+	}
+	if r.NoDefaultAlpn {
+		rr.Value = append(rr.Value, &dns.SVCBNoDefaultAlpn{})
+	}
+	if r.Port != 0 {
+		rr.Value = append(rr.Value, &dns.SVCBPort{Port: r.Port})
+	}
+	if len(r.IPv4Hint) != 0 {
+		v := &dns.SVCBIPv4Hint{}
+		for _, s := range r.IPv4Hint {
+			ip := net.ParseIP(s)
+			if ip == nil || ip.To4() == nil {
+				return nil, fmt.Errorf("invalid ipv4hint: %s", s)
+			}
+			v.Hint = append(v.Hint, ip.To4())
+		}
+		rr.Value = append(rr.Value, v)
+	}
+	if r.ECH != "" {
+		b, err := base64.StdEncoding.DecodeString(r.ECH)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ech: %w", err)
+		}
+		rr.Value = append(rr.Value, &dns.SVCBECHConfig{ECH: b})
+	}
+	if len(r.IPv6Hint) != 0 {
+		v := &dns.SVCBIPv6Hint{}
+		for _, s := range r.IPv6Hint {
+			ip := net.ParseIP(s)
+			if ip == nil || ip.To4() != nil {
+				return nil, fmt.Errorf("invalid ipv6hint: %s", s)
+			}
+			v.Hint = append(v.Hint, ip)
+		}
+		rr.Value = append(rr.Value, v)
 	}
+	return rr, nil
 }
 
 func Name(r *v1alpha1.DNSRecord) string {
 	switch {
 	case r.Spec.A != nil:
 		return r.Spec.A.Name
+	case r.Spec.AAAA != nil:
+		return r.Spec.AAAA.Name
 	case r.Spec.TXT != nil:
 		return r.Spec.TXT.Name
 	case r.Spec.SRV != nil:
@@ -201,6 +535,24 @@ func Name(r *v1alpha1.DNSRecord) string {
 		return r.Spec.MX.Name
 	case r.Spec.CNAME != nil:
 		return r.Spec.CNAME.Name
+	case r.Spec.CAA != nil:
+		return r.Spec.CAA.Name
+	case r.Spec.NS != nil:
+		return r.Spec.NS.Name
+	case r.Spec.PTR != nil:
+		return r.Spec.PTR.Name
+	case r.Spec.SVCB != nil:
+		return r.Spec.SVCB.Name
+	case r.Spec.HTTPS != nil:
+		return r.Spec.HTTPS.Name
+	case r.Spec.TLSA != nil:
+		return r.Spec.TLSA.Name
+	case r.Spec.SPF != nil:
+		return r.Spec.SPF.Name
+	case r.Spec.DMARC != nil:
+		return r.Spec.DMARC.Name
+	case r.Spec.DKIM != nil:
+		return r.Spec.DKIM.Name
 	default:
 		rr, err := dns.NewRR(r.Spec.Raw)
 		if err != nil {
@@ -0,0 +1,295 @@
+package record
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.linka.cloud/k8s/dns/api/v1alpha1"
+)
+
+func TestToRR(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	tests := []struct {
+		name string
+		spec v1alpha1.DNSRecordSpec
+		want []string
+		err  bool
+	}{
+		{
+			name: "a",
+			spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Ttl: 60, Target: "10.0.0.1", Targets: []string{"10.0.0.2"}}},
+			want: []string{"example.org. 60 IN A 10.0.0.1", "example.org. 60 IN A 10.0.0.2"},
+		},
+		{
+			name: "a with ipv6",
+			spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Targets: []string{"2001:db8::1"}}},
+			err:  true,
+		},
+		{
+			name: "a without targets",
+			spec: v1alpha1.DNSRecordSpec{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1}},
+			err:  true,
+		},
+		{
+			name: "aaaa",
+			spec: v1alpha1.DNSRecordSpec{AAAA: &v1alpha1.AAAARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"2001:db8::1", "2001:db8::2"}}},
+			want: []string{"example.org. 60 IN AAAA 2001:db8::1", "example.org. 60 IN AAAA 2001:db8::2"},
+		},
+		{
+			name: "aaaa with ipv4",
+			spec: v1alpha1.DNSRecordSpec{AAAA: &v1alpha1.AAAARecord{Name: "example.org.", Class: 1, Targets: []string{"10.0.0.1"}}},
+			err:  true,
+		},
+		{
+			name: "cname",
+			spec: v1alpha1.DNSRecordSpec{CNAME: &v1alpha1.CNAMERecord{Name: "www.example.org.", Class: 1, Ttl: 60, Target: "example.org."}},
+			want: []string{"www.example.org. 60 IN CNAME example.org."},
+		},
+		{
+			name: "txt",
+			spec: v1alpha1.DNSRecordSpec{TXT: &v1alpha1.TXTRecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"hello world", long}}},
+			want: []string{`example.org. 60 IN TXT "hello world" "` + long[:255] + `" "` + long[255:] + `"`},
+		},
+		{
+			name: "empty txt",
+			spec: v1alpha1.DNSRecordSpec{TXT: &v1alpha1.TXTRecord{Name: "example.org.", Class: 1}},
+			err:  true,
+		},
+		{
+			name: "srv",
+			spec: v1alpha1.DNSRecordSpec{SRV: &v1alpha1.SRVRecord{Name: "_sip._tcp.example.org.", Class: 1, Ttl: 60, Priority: 10, Weight: 1, Port: 5060, Target: "sip.example.org."}},
+			want: []string{"_sip._tcp.example.org. 60 IN SRV 10 1 5060 sip.example.org."},
+		},
+		{
+			name: "mx",
+			spec: v1alpha1.DNSRecordSpec{MX: &v1alpha1.MXRecord{Name: "example.org.", Class: 1, Ttl: 60, Preference: 10, Target: "mail.example.org."}},
+			want: []string{"example.org. 60 IN MX 10 mail.example.org."},
+		},
+		{
+			name: "caa",
+			spec: v1alpha1.DNSRecordSpec{CAA: &v1alpha1.CAARecord{Name: "example.org.", Class: 1, Ttl: 60, Tag: "issue", Value: "letsencrypt.org"}},
+			want: []string{`example.org. 60 IN CAA 0 issue "letsencrypt.org"`},
+		},
+		{
+			name: "caa without tag",
+			spec: v1alpha1.DNSRecordSpec{CAA: &v1alpha1.CAARecord{Name: "example.org.", Class: 1, Value: "letsencrypt.org"}},
+			err:  true,
+		},
+		{
+			name: "ns",
+			spec: v1alpha1.DNSRecordSpec{NS: &v1alpha1.NSRecord{Name: "sub.example.org.", Class: 1, Ttl: 60, Targets: []string{"ns1.example.org.", "ns2.example.org."}}},
+			want: []string{"sub.example.org. 60 IN NS ns1.example.org.", "sub.example.org. 60 IN NS ns2.example.org."},
+		},
+		{
+			name: "ns with empty target",
+			spec: v1alpha1.DNSRecordSpec{NS: &v1alpha1.NSRecord{Name: "sub.example.org.", Class: 1, Targets: []string{""}}},
+			err:  true,
+		},
+		{
+			name: "ptr",
+			spec: v1alpha1.DNSRecordSpec{PTR: &v1alpha1.PTRRecord{Name: "1.0.0.10.in-addr.arpa.", Class: 1, Ttl: 60, Target: "example.org."}},
+			want: []string{"1.0.0.10.in-addr.arpa. 60 IN PTR example.org."},
+		},
+		{
+			name: "svcb",
+			spec: v1alpha1.DNSRecordSpec{SVCB: &v1alpha1.SVCBRecord{Name: "_8443._foo.example.org.", Class: 1, Ttl: 60, Priority: 1, Target: ".", Alpn: []string{"h2"}, Port: 8443, IPv4Hint: []string{"10.0.0.1"}, IPv6Hint: []string{"2001:db8::1"}}},
+			want: []string{`_8443._foo.example.org. 60 IN SVCB 1 . alpn="h2" port="8443" ipv4hint="10.0.0.1" ipv6hint="2001:db8::1"`},
+		},
+		{
+			name: "https",
+			spec: v1alpha1.DNSRecordSpec{HTTPS: &v1alpha1.SVCBRecord{Name: "example.org.", Class: 1, Ttl: 60, Priority: 1, Target: ".", Alpn: []string{"h2", "h3"}}},
+			want: []string{`example.org. 60 IN HTTPS 1 . alpn="h2,h3"`},
+		},
+		{
+			name: "svcb with invalid hint",
+			spec: v1alpha1.DNSRecordSpec{SVCB: &v1alpha1.SVCBRecord{Name: "example.org.", Class: 1, Priority: 1, Target: ".", IPv4Hint: []string{"2001:db8::1"}}},
+			err:  true,
+		},
+		{
+			name: "tlsa",
+			spec: v1alpha1.DNSRecordSpec{TLSA: &v1alpha1.TLSARecord{Name: "_443._tcp.example.org.", Class: 1, Ttl: 60, Usage: 3, Selector: 1, MatchingType: 1, Certificate: "abcdef"}},
+			want: []string{"_443._tcp.example.org. 60 IN TLSA 3 1 1 abcdef"},
+		},
+		{
+			name: "spf",
+			spec: v1alpha1.DNSRecordSpec{SPF: &v1alpha1.SPFRecord{Name: "example.org.", Class: 1, Ttl: 60, Mechanisms: []string{"mx", "-all"}}},
+			want: []string{`example.org. 60 IN TXT "v=spf1 mx -all"`},
+		},
+		{
+			name: "dmarc",
+			spec: v1alpha1.DNSRecordSpec{DMARC: &v1alpha1.DMARCRecord{Name: "_dmarc.example.org.", Class: 1, Ttl: 60, Policy: "reject", ReportAggregate: []string{"mailto:dmarc@example.org"}}},
+			want: []string{`_dmarc.example.org. 60 IN TXT "v=DMARC1; p=reject; rua=mailto:dmarc@example.org"`},
+		},
+		{
+			name: "dkim",
+			spec: v1alpha1.DNSRecordSpec{DKIM: &v1alpha1.DKIMRecord{Name: "mail._domainkey.example.org.", Class: 1, Ttl: 60, KeyType: "rsa", PublicKey: long}},
+			want: []string{`mail._domainkey.example.org. 60 IN TXT "` + ("v=DKIM1; k=rsa; p=" + long)[:255] + `" "` + ("v=DKIM1; k=rsa; p=" + long)[255:] + `"`},
+		},
+		{
+			name: "dkim without public key",
+			spec: v1alpha1.DNSRecordSpec{DKIM: &v1alpha1.DKIMRecord{Name: "mail._domainkey.example.org.", Class: 1, KeyType: "rsa"}},
+			err:  true,
+		},
+		{
+			name: "raw",
+			spec: v1alpha1.DNSRecordSpec{Raw: `example.org. 60 IN HINFO "cpu" "os"`},
+			want: []string{`example.org. 60 IN HINFO "cpu" "os"`},
+		},
+		{
+			name: "invalid raw",
+			spec: v1alpha1.DNSRecordSpec{Raw: "example.org. IN A not-an-ip"},
+			err:  true,
+		},
+		{
+			name: "empty",
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToRR(v1alpha1.DNSRecord{Spec: tt.spec})
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.want))
+			for i, v := range tt.want {
+				assert.Equal(t, mustRR(t, v).String(), got[i].String())
+			}
+		})
+	}
+}
+
+func TestFromRRs(t *testing.T) {
+	tests := []struct {
+		name string
+		rrs  []string
+		want []v1alpha1.DNSRecordSpec
+	}{
+		{
+			name: "a rrset",
+			rrs:  []string{"example.org. 60 IN A 10.0.0.1", "example.org. 60 IN A 10.0.0.2"},
+			want: []v1alpha1.DNSRecordSpec{
+				{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"10.0.0.1", "10.0.0.2"}}},
+			},
+		},
+		{
+			name: "aaaa rrset",
+			rrs:  []string{"example.org. 60 IN AAAA 2001:db8::1", "example.org. 60 IN AAAA 2001:db8::2"},
+			want: []v1alpha1.DNSRecordSpec{
+				{AAAA: &v1alpha1.AAAARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"2001:db8::1", "2001:db8::2"}}},
+			},
+		},
+		{
+			name: "ns rrset",
+			rrs:  []string{"sub.example.org. 60 IN NS ns1.example.org.", "sub.example.org. 60 IN NS ns2.example.org."},
+			want: []v1alpha1.DNSRecordSpec{
+				{NS: &v1alpha1.NSRecord{Name: "sub.example.org.", Class: 1, Ttl: 60, Targets: []string{"ns1.example.org.", "ns2.example.org."}}},
+			},
+		},
+		{
+			name: "different names are not merged",
+			rrs:  []string{"example.org. 60 IN A 10.0.0.1", "www.example.org. 60 IN A 10.0.0.2"},
+			want: []v1alpha1.DNSRecordSpec{
+				{A: &v1alpha1.ARecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"10.0.0.1"}}},
+				{A: &v1alpha1.ARecord{Name: "www.example.org.", Class: 1, Ttl: 60, Targets: []string{"10.0.0.2"}}},
+			},
+		},
+		{
+			name: "txt",
+			rrs:  []string{`example.org. 60 IN TXT "a" "b"`},
+			want: []v1alpha1.DNSRecordSpec{
+				{TXT: &v1alpha1.TXTRecord{Name: "example.org.", Class: 1, Ttl: 60, Targets: []string{"a", "b"}}},
+			},
+		},
+		{
+			name: "caa",
+			rrs:  []string{`example.org. 60 IN CAA 128 issue "letsencrypt.org"`},
+			want: []v1alpha1.DNSRecordSpec{
+				{CAA: &v1alpha1.CAARecord{Name: "example.org.", Class: 1, Ttl: 60, Flag: 128, Tag: "issue", Value: "letsencrypt.org"}},
+			},
+		},
+		{
+			name: "https",
+			rrs:  []string{`example.org. 60 IN HTTPS 1 . alpn="h2,h3" port="443" ipv4hint="10.0.0.1"`},
+			want: []v1alpha1.DNSRecordSpec{
+				{HTTPS: &v1alpha1.SVCBRecord{Name: "example.org.", Class: 1, Ttl: 60, Priority: 1, Target: ".", Alpn: []string{"h2", "h3"}, Port: 443, IPv4Hint: []string{"10.0.0.1"}}},
+			},
+		},
+		{
+			name: "svcb with unsupported parameter",
+			rrs:  []string{`example.org. 60 IN SVCB 1 . mandatory="alpn" alpn="h2"`},
+			want: []v1alpha1.DNSRecordSpec{
+				{Raw: "example.org.\t60\tIN\tSVCB\t1 . mandatory=\"alpn\" alpn=\"h2\""},
+			},
+		},
+		{
+			name: "tlsa",
+			rrs:  []string{"_443._tcp.example.org. 60 IN TLSA 3 1 1 abcdef"},
+			want: []v1alpha1.DNSRecordSpec{
+				{TLSA: &v1alpha1.TLSARecord{Name: "_443._tcp.example.org.", Class: 1, Ttl: 60, Usage: 3, Selector: 1, MatchingType: 1, Certificate: "abcdef"}},
+			},
+		},
+		{
+			name: "unsupported type",
+			rrs:  []string{`example.org. 60 IN HINFO "cpu" "os"`},
+			want: []v1alpha1.DNSRecordSpec{
+				{Raw: "example.org.\t60\tIN\tHINFO\t\"cpu\" \"os\""},
+			},
+		},
+		{
+			name: "idn",
+			rrs:  []string{"bücher.example. 60 IN CNAME bücher.example.org."},
+			want: []v1alpha1.DNSRecordSpec{
+				{CNAME: &v1alpha1.CNAMERecord{Name: "xn--bcher-kva.example.", Class: 1, Ttl: 60, Target: "xn--bcher-kva.example.org."}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rrs []dns.RR
+			for _, v := range tt.rrs {
+				rrs = append(rrs, mustRR(t, v))
+			}
+			got := FromRRs(rrs)
+			require.Len(t, got, len(tt.want))
+			for i, v := range tt.want {
+				assert.Equal(t, v, got[i].Spec)
+			}
+		})
+	}
+}
+
+func mustRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+	require.NotNil(t, rr)
+	return rr
+}
+
+func TestToRRSecretData(t *testing.T) {
+	tlsa := v1alpha1.DNSRecord{
+		Spec:   v1alpha1.DNSRecordSpec{TLSA: &v1alpha1.TLSARecord{Name: "_443._tcp.example.org.", Class: 1, Ttl: 60, Usage: 3, Selector: 1, MatchingType: 1, Certificate: "abcdef", SecretName: "tls"}},
+		Status: v1alpha1.DNSRecordStatus{SecretData: "012345"},
+	}
+	got, err := ToRR(tlsa)
+	require.NoError(t, err)
+	assert.Equal(t, mustRR(t, "_443._tcp.example.org. 60 IN TLSA 3 1 1 012345").String(), got[0].String())
+	tlsa.Status.SecretData = ""
+	_, err = ToRR(tlsa)
+	assert.Error(t, err)
+
+	dkim := v1alpha1.DNSRecord{
+		Spec:   v1alpha1.DNSRecordSpec{DKIM: &v1alpha1.DKIMRecord{Name: "mail._domainkey.example.org.", Class: 1, Ttl: 60, KeyType: "ed25519", SecretName: "dkim"}},
+		Status: v1alpha1.DNSRecordStatus{SecretData: "key"},
+	}
+	got, err = ToRR(dkim)
+	require.NoError(t, err)
+	assert.Equal(t, mustRR(t, `mail._domainkey.example.org. 60 IN TXT "v=DKIM1; k=ed25519; p=key"`).String(), got[0].String())
+	assert.Empty(t, dkim.Spec.DKIM.PublicKey)
+}
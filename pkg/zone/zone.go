@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Linka Cloud Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+const (
+	ReverseV4 = "in-addr.arpa."
+	ReverseV6 = "ip6.arpa."
+
+	DefaultReverseV4Prefix = 24
+	DefaultReverseV6Prefix = 48
+)
+
+var (
+	// reverseV4Labels is the number of address labels in an ipv4 reverse zone, e.g. 2.0.192.in-addr.arpa. (/24)
+	reverseV4Labels = DefaultReverseV4Prefix / 8
+	// reverseV6Labels is the number of address labels (nibbles) in an ipv6 reverse zone (/48)
+	reverseV6Labels = DefaultReverseV6Prefix / 4
+)
+
+// SetReversePrefixes sets the prefix length of the reverse zones the reverse names belong to when no DNSZone is declared.
+// The ipv4 prefix must be a multiple of 8 and the ipv6 prefix a multiple of 4.
+// It is not safe for concurrent use and must be called before resolving any zone.
+func SetReversePrefixes(v4, v6 int) error {
+	if v4 <= 0 || v4 >= 32 || v4%8 != 0 {
+		return fmt.Errorf("invalid ipv4 reverse zone prefix: /%d: must be 8, 16 or 24", v4)
+	}
+	if v6 <= 0 || v6 >= 128 || v6%4 != 0 {
+		return fmt.Errorf("invalid ipv6 reverse zone prefix: /%d: must be a multiple of 4 lower than 128", v6)
+	}
+	reverseV4Labels, reverseV6Labels = v4/8, v6/4
+	return nil
+}
+
+// For returns the fully qualified zone the name belongs to.
+// Forward names are resolved using the public suffix list, reverse names
+// belong to the /24 (in-addr.arpa.) or /48 (ip6.arpa.) reverse zone, unless configured otherwise.
+func For(name string) (string, error) {
+	name = dns.Fqdn(strings.ToLower(name))
+	parts := dns.SplitDomainName(name)
+	if len(parts) < 2 {
+		return "", fmt.Errorf("malformed name: %s", name)
+	}
+	if z, ok := reverse(name, parts); ok {
+		return z, nil
+	}
+	d, err := publicsuffix.Domain(strings.Join(parts, "."))
+	if err != nil {
+		return "", err
+	}
+	return dns.Fqdn(d), nil
+}
+
+// Match returns the longest of the zones containing the name
+func Match(name string, zones []string) (string, bool) {
+	name = dns.Fqdn(strings.ToLower(name))
+	var match string
+	for _, v := range zones {
+		v = dns.Fqdn(strings.ToLower(v))
+		if dns.IsSubDomain(v, name) && len(v) > len(match) {
+			match = v
+		}
+	}
+	return match, match != ""
+}
+
+// IsReverse returns true if the name is an in-addr.arpa. or ip6.arpa. name
+func IsReverse(name string) bool {
+	name = dns.Fqdn(strings.ToLower(name))
+	return dns.IsSubDomain(ReverseV4, name) || dns.IsSubDomain(ReverseV6, name)
+}
+
+func reverse(name string, parts []string) (string, bool) {
+	var labels int
+	switch {
+	case dns.IsSubDomain(ReverseV4, name):
+		labels = reverseV4Labels + 2
+	case dns.IsSubDomain(ReverseV6, name):
+		labels = reverseV6Labels + 2
+	default:
+		return "", false
+	}
+	if len(parts) < labels {
+		return "", false
+	}
+	return dns.Fqdn(strings.Join(parts[len(parts)-labels:], ".")), true
+}
@@ -0,0 +1,71 @@
+package zone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{name: "www.example.org.", want: "example.org."},
+		{name: "a.b.example.co.uk", want: "example.co.uk."},
+		{name: "Example.ORG.", want: "example.org."},
+		{name: "10.2.0.192.in-addr.arpa.", want: "2.0.192.in-addr.arpa."},
+		{name: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", want: "0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."},
+		{name: "localhost", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := For(tt.name)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	zones := []string{"example.org.", "team.example.org", "cluster.local."}
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{name: "www.example.org.", want: "example.org.", ok: true},
+		{name: "example.org", want: "example.org.", ok: true},
+		{name: "www.Team.example.org.", want: "team.example.org.", ok: true},
+		{name: "svc.cluster.local.", want: "cluster.local.", ok: true},
+		{name: "www.example.com.", ok: false},
+		{name: "badexample.org.", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Match(tt.name, zones)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSetReversePrefixes(t *testing.T) {
+	defer SetReversePrefixes(DefaultReverseV4Prefix, DefaultReverseV6Prefix)
+	require.Error(t, SetReversePrefixes(20, 48))
+	require.Error(t, SetReversePrefixes(24, 50))
+	require.Error(t, SetReversePrefixes(32, 48))
+	require.NoError(t, SetReversePrefixes(16, 64))
+	got, err := For("10.2.0.192.in-addr.arpa.")
+	require.NoError(t, err)
+	assert.Equal(t, "0.192.in-addr.arpa.", got)
+	got, err = For("1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", got)
+}